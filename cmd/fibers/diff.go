@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/long2ice/fibers/swagger/diff"
+)
+
+// errBreaking makes the command fail without printing more than the report
+var errBreaking = errors.New("breaking changes found")
+
+func compare(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	format := flags.String("format", "text", "text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: fibers diff [-format text|json] base revision")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+	base, err := diff.Load(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+	revision, err := diff.Load(flags.Arg(1))
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(1), err)
+	}
+	report := diff.Compare(base, revision)
+	switch *format {
+	case "text":
+		err = report.WriteText(os.Stdout)
+	case "json":
+		err = report.WriteJSON(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format %q, expected text or json", *format)
+	}
+	if err != nil {
+		return err
+	}
+	if len(report.Breaking()) > 0 {
+		return errBreaking
+	}
+	return nil
+}
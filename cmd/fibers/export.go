@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// exportMain is the program run to export the document of the app returned by a function
+const exportMain = `package main
+
+import (
+	"fmt"
+	"os"
+
+	app %q
+)
+
+func main() {
+	if err := app.%s().Export(os.Stdout, %q); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`
+
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "", "file to write the document to, stdout if empty")
+	format := flags.String("format", "", "json or yaml, by default the extension of -o or json")
+	check := flags.Bool("check", false, "fail if the -o file differs from the document instead of writing it")
+	function := flags.String("func", "NewApp", "function of the package returning the *fibers.App")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: fibers export [-o file] [-format json|yaml] [-check] [-func name] [package]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	pkg := "."
+	if flags.NArg() > 0 {
+		pkg = flags.Arg(0)
+	}
+	if *format == "" {
+		*format = "json"
+		if ext := filepath.Ext(*output); ext == ".yaml" || ext == ".yml" {
+			*format = "yaml"
+		}
+	}
+	if *format != "json" && *format != "yaml" {
+		return fmt.Errorf("unknown format %q, expected json or yaml", *format)
+	}
+	if !token.IsIdentifier(*function) || !token.IsExported(*function) {
+		return fmt.Errorf("-func %q is not the name of an exported function", *function)
+	}
+	if *check && *output == "" {
+		return errors.New("-check needs the file to compare with as -o")
+	}
+	data, err := runExport(pkg, *function, *format)
+	if err != nil {
+		return err
+	}
+	return writeDocument(*output, data, *check)
+}
+
+// writeDocument writes data to output, or stdout if it is empty, or with check compares it
+// with the content of output instead
+func writeDocument(output string, data []byte, check bool) error {
+	if check {
+		committed, err := os.ReadFile(output)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(committed, data) {
+			return fmt.Errorf("%s is out of date, run fibers export -o %s", output, output)
+		}
+		return nil
+	}
+	if output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(output, data, 0o644)
+}
+
+// runExport runs a program calling Export on the app returned by function of pkg. The program
+// is written to a temporary directory and overlaid on a directory of pkg with go run -overlay,
+// so that it builds with the module of pkg while nothing is written to its source tree. Only
+// the function runs, not the main of the app.
+func runExport(pkg string, function string, format string) ([]byte, error) {
+	out, err := exec.Command("go", "list", "-f", "{{.Dir}}\n{{.ImportPath}}\n{{.Name}}", pkg).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("go list %s: %s", pkg, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return nil, err
+	}
+	fields := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("go list %s: unexpected output %q", pkg, out)
+	}
+	dir, importPath, name := fields[0], fields[1], fields[2]
+	if name == "main" {
+		return nil, fmt.Errorf("%s is a main package, which can't be imported, "+
+			"move the app to a func %s() *fibers.App of another package", pkg, function)
+	}
+	tmp, err := os.MkdirTemp("", "fibers-export")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	overlay, err := writeOverlay(tmp, dir, fmt.Sprintf(exportMain, importPath, function, format))
+	if err != nil {
+		return nil, err
+	}
+	var stdout bytes.Buffer
+	// directories starting with _ are left out of patterns such as ./...
+	cmd := exec.Command("go", "run", "-overlay", overlay, "./_fibers_export")
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err = runInterruptible(cmd); err != nil {
+		return nil, fmt.Errorf("exporting %s.%s: %w", importPath, function, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// writeOverlay writes source to tmp along with an overlay adding it as the main.go of a
+// _fibers_export directory of dir, and returns the path of the overlay
+func writeOverlay(tmp string, dir string, source string) (string, error) {
+	main := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(main, []byte(source), 0o644); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(map[string]map[string]string{
+		"Replace": {filepath.Join(dir, "_fibers_export", "main.go"): main},
+	})
+	if err != nil {
+		return "", err
+	}
+	overlay := filepath.Join(tmp, "overlay.json")
+	return overlay, os.WriteFile(overlay, data, 0o644)
+}
+
+// runInterruptible runs cmd, passing on interrupts to it instead of exiting, so that the
+// caller still cleans up once cmd has stopped
+func runInterruptible(cmd *exec.Cmd) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case sig := <-signals:
+			_ = cmd.Process.Signal(sig)
+		}
+	}
+}
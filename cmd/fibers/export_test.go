@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteDocument(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "openapi.json")
+	if err := writeDocument(output, []byte(`{"openapi":"3.0.3"}`), false); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"up to date", `{"openapi":"3.0.3"}`, ""},
+		{"out of date", `{"openapi":"3.1.0"}`, "is out of date"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := writeDocument(output, []byte(test.data), true)
+			if test.err == "" && err != nil || test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Errorf("got %v, want %q", err, test.err)
+			}
+		})
+	}
+	// check never writes the file
+	if data, _ := os.ReadFile(output); string(data) != `{"openapi":"3.0.3"}` {
+		t.Errorf("file changed to %s", data)
+	}
+	if err := writeDocument(filepath.Join(dir, "missing.json"), nil, true); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
+
+func TestExportFlags(t *testing.T) {
+	tests := [][]string{
+		{"-format", "xml"},
+		{"-func", "newApp"},
+		{"-check"},
+	}
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			if err := export(args); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestRunExport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds an app")
+	}
+	data, err := runExport("./testdata/app", "NewApp", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	if err = json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v: %s", err, data)
+	}
+	if _, ok := doc.Paths["/users/{id}"]; !ok {
+		t.Errorf("no /users/{id} in %s", data)
+	}
+	// the program is built from a temporary directory, never from the one of the package
+	entries, err := os.ReadDir("./testdata/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "_fibers_export") {
+			t.Errorf("%s left in the package directory", entry.Name())
+		}
+	}
+	if _, err = runExport("./testdata/app", "Missing", "json"); err == nil {
+		t.Error("no error for a missing function")
+	}
+	if _, err = runExport("../../examples", "NewApp", "json"); err == nil || !strings.Contains(err.Error(), "main package") {
+		t.Errorf("got %v for a main package", err)
+	}
+}
+
+func TestWriteOverlay(t *testing.T) {
+	tmp, dir := t.TempDir(), filepath.Join("module", "api")
+	overlay, err := writeOverlay(tmp, dir, "package main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Replace map[string]string
+	}
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	main := got.Replace[filepath.Join(dir, "_fibers_export", "main.go")]
+	if filepath.Dir(main) != tmp || len(got.Replace) != 1 {
+		t.Fatalf("got overlay %s", data)
+	}
+	if source, _ := os.ReadFile(main); string(source) != "package main" {
+		t.Errorf("got source %q", source)
+	}
+}
@@ -0,0 +1,53 @@
+// Command fibers works with the OpenAPI documents of fibers apps.
+//
+//	fibers export [-o file] [-format json|yaml] [-check] [-func name] [package]
+//
+// calls the function of the package, ./ by default, named by -func, NewApp by default,
+// which returns the *fibers.App, and writes its document with App.Export, without running
+// the main of the app. With -check it fails if the document differs from the one in the -o
+// file, to catch specs that are out of date in CI.
+//
+//	fibers diff [-format text|json] base revision
+//
+// compares two documents, such as the one exported for the previous release and the current
+// one, and fails if any change breaks clients of the base document.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `usage: fibers <command> [arguments]
+
+commands:
+  export  write the OpenAPI document of an app without starting it
+  diff    report the changes between two OpenAPI documents, failing on breaking ones
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = export(os.Args[2:])
+	case "diff":
+		err = compare(os.Args[2:])
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "fibers: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		if err != flag.ErrHelp && err != errBreaking {
+			fmt.Fprintln(os.Stderr, "fibers:", err)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,25 @@
+// Package app builds the app exported by the tests of the fibers command.
+package app
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers"
+	"github.com/long2ice/fibers/router"
+	"github.com/long2ice/fibers/swagger"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+type userReq struct {
+	ID string `uri:"id" validate:"required"`
+}
+
+func NewApp() *fibers.App {
+	app := fibers.New(swagger.New("test", "test", "1.0.0"), fiber.Config{})
+	app.Get("/users/:id", router.NewR(func(c *fiber.Ctx, req userReq) (user, error) {
+		return user{Name: req.ID}, nil
+	}))
+	return app
+}
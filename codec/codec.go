@@ -0,0 +1,61 @@
+package codec
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const (
+	MIMEApplicationMsgPack  = "application/msgpack"
+	MIMEApplicationXMsgPack = "application/x-msgpack"
+	MIMEApplicationProtobuf = "application/x-protobuf"
+	MIMEProtobuf            = "application/protobuf"
+)
+
+// Codec decodes and encodes bodies of a content type
+type Codec interface {
+	Decode(data []byte, out interface{}) error
+	Encode(in interface{}) ([]byte, error)
+	// Schema returns the schema documented for a body, given the one generated from the model
+	// and the type of the model, which is nil for bodies without one
+	Schema(schema *openapi3.Schema, type_ reflect.Type) *openapi3.Schema
+}
+
+// Registry holds codecs keyed by MIME type
+type Registry struct {
+	codecs map[string]Codec
+}
+
+// Default is used by routers not mounted on an App
+var Default = NewRegistry()
+
+// NewRegistry returns a registry with JSON, XML, MessagePack and Protobuf codecs
+func NewRegistry() *Registry {
+	registry := &Registry{codecs: make(map[string]Codec)}
+	registry.Register(fiber.MIMEApplicationJSON, &JSON{})
+	registry.Register(fiber.MIMEApplicationXML, &XML{})
+	registry.Register(fiber.MIMETextXML, &XML{})
+	registry.Register(MIMEApplicationMsgPack, &MsgPack{})
+	registry.Register(MIMEApplicationXMsgPack, &MsgPack{})
+	registry.Register(MIMEApplicationProtobuf, &Protobuf{})
+	registry.Register(MIMEProtobuf, &Protobuf{})
+	return registry
+}
+
+func (registry *Registry) Register(mime string, codec Codec) {
+	registry.codecs[strings.ToLower(mime)] = codec
+}
+
+// Get returns the codec of contentType, ignoring its parameters and vendor prefix
+func (registry *Registry) Get(contentType string) (Codec, bool) {
+	mime := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	codec, ok := registry.codecs[mime]
+	if !ok {
+		codec, ok = registry.codecs[utils.ParseVendorSpecificContentType(mime)]
+	}
+	return codec, ok
+}
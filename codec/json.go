@@ -0,0 +1,33 @@
+package codec
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// JSON uses encoding/json unless Marshal or Unmarshal is set, such as fiber's JSONEncoder and JSONDecoder
+type JSON struct {
+	Marshal   utils.JSONMarshal
+	Unmarshal utils.JSONUnmarshal
+}
+
+func (j *JSON) Decode(data []byte, out interface{}) error {
+	if j.Unmarshal != nil {
+		return j.Unmarshal(data, out)
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (j *JSON) Encode(in interface{}) ([]byte, error) {
+	if j.Marshal != nil {
+		return j.Marshal(in)
+	}
+	return json.Marshal(in)
+}
+
+func (j *JSON) Schema(schema *openapi3.Schema, _ reflect.Type) *openapi3.Schema {
+	return schema
+}
@@ -0,0 +1,32 @@
+package codec
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgPack reads field names from json tags, so bodies match the documented schema
+type MsgPack struct{}
+
+func (m *MsgPack) Decode(data []byte, out interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(out)
+}
+
+func (m *MsgPack) Encode(in interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(in); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *MsgPack) Schema(schema *openapi3.Schema, _ reflect.Type) *openapi3.Schema {
+	return schema
+}
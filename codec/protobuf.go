@@ -0,0 +1,35 @@
+package codec
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"google.golang.org/protobuf/proto"
+)
+
+// Protobuf works with models generated by protoc-gen-go
+type Protobuf struct{}
+
+func (p *Protobuf) Decode(data []byte, out interface{}) error {
+	message, ok := out.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto.Message", out)
+	}
+	return proto.Unmarshal(data, message)
+}
+
+func (p *Protobuf) Encode(in interface{}) ([]byte, error) {
+	message, ok := in.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto.Message", in)
+	}
+	return proto.Marshal(message)
+}
+
+// Schema documents the body as binary as the wire format is not described by the model
+func (p *Protobuf) Schema(schema *openapi3.Schema, _ reflect.Type) *openapi3.Schema {
+	binary := openapi3.NewStringSchema().WithFormat("binary")
+	binary.Description = schema.Description
+	return binary
+}
@@ -0,0 +1,272 @@
+package codec
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/long2ice/fibers/constants"
+)
+
+var xmlNameType = reflect.TypeOf(xml.Name{})
+
+// XML uses encoding/xml, which reads element names from xml tags or the names of the fields
+type XML struct{}
+
+func (x *XML) Decode(data []byte, out interface{}) error {
+	return xml.Unmarshal(data, out)
+}
+
+func (x *XML) Encode(in interface{}) ([]byte, error) {
+	return xml.Marshal(in)
+}
+
+// Schema names the root element and the properties generated from json tags after the
+// elements and attributes encoding/xml reads, and leaves out fields tagged xml:"-". The
+// schemas changed are copies, so components documented for other content types are kept.
+func (x *XML) Schema(schema *openapi3.Schema, type_ reflect.Type) *openapi3.Schema {
+	if type_ == nil {
+		return schema
+	}
+	for type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	ret := xmlSchema(schema, type_, make(map[reflect.Type]bool))
+	if name := xmlRootName(type_); name != "" {
+		if ret == schema {
+			copy := *schema
+			ret = &copy
+		}
+		ret.XML = &openapi3.XML{Name: name}
+	}
+	return ret
+}
+
+// xmlField is how encoding/xml reads the field documented as a property
+type xmlField struct {
+	type_   reflect.Type
+	name    string
+	attr    bool
+	ignored bool
+}
+
+func xmlSchema(schema *openapi3.Schema, type_ reflect.Type, visiting map[reflect.Type]bool) *openapi3.Schema {
+	for type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	// nullable references are wrapped in allOf
+	if len(schema.AllOf) == 1 && schema.Type == "" && len(schema.Properties) == 0 {
+		ref := xmlSchemaRef(schema.AllOf[0], type_, visiting)
+		if ref == schema.AllOf[0] {
+			return schema
+		}
+		copy := *schema
+		copy.AllOf = openapi3.SchemaRefs{ref}
+		return &copy
+	}
+	switch type_.Kind() {
+	case reflect.Slice, reflect.Array:
+		if type_.Elem().Kind() == reflect.Uint8 || schema.Items == nil {
+			return schema
+		}
+		items := xmlSchemaRef(schema.Items, type_.Elem(), visiting)
+		if items == schema.Items {
+			return schema
+		}
+		copy := *schema
+		copy.Items = items
+		return &copy
+	case reflect.Struct:
+		// recursive types keep referencing their component
+		if visiting[type_] {
+			return schema
+		}
+		visiting[type_] = true
+		defer delete(visiting, type_)
+		return xmlStruct(schema, type_, visiting)
+	}
+	return schema
+}
+
+func xmlSchemaRef(ref *openapi3.SchemaRef, type_ reflect.Type, visiting map[reflect.Type]bool) *openapi3.SchemaRef {
+	if ref == nil || ref.Value == nil {
+		return ref
+	}
+	schema := xmlSchema(ref.Value, type_, visiting)
+	if schema == ref.Value {
+		return ref
+	}
+	return openapi3.NewSchemaRef("", schema)
+}
+
+func xmlStruct(schema *openapi3.Schema, type_ reflect.Type, visiting map[reflect.Type]bool) *openapi3.Schema {
+	fields := make(map[string]xmlField)
+	xmlFields(type_, fields)
+	var properties openapi3.Schemas
+	removed := make(map[string]bool)
+	for name, ref := range schema.Properties {
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var property *openapi3.SchemaRef
+		if !field.ignored {
+			property = xmlProperty(ref, name, field, visiting)
+			if property == ref {
+				continue
+			}
+		}
+		if properties == nil {
+			properties = make(openapi3.Schemas, len(schema.Properties))
+			for name, ref := range schema.Properties {
+				properties[name] = ref
+			}
+		}
+		if property == nil {
+			delete(properties, name)
+			removed[name] = true
+		} else {
+			properties[name] = property
+		}
+	}
+	if properties == nil {
+		return schema
+	}
+	copy := *schema
+	copy.Properties = properties
+	copy.Required = nil
+	for _, name := range schema.Required {
+		if !removed[name] {
+			copy.Required = append(copy.Required, name)
+		}
+	}
+	return &copy
+}
+
+// xmlProperty names the schema of a property after its element or attribute, or the items of
+// arrays after the elements repeated for them
+func xmlProperty(ref *openapi3.SchemaRef, name string, field xmlField, visiting map[reflect.Type]bool) *openapi3.SchemaRef {
+	type_ := field.type_
+	for type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	kind := type_.Kind()
+	if (kind == reflect.Slice || kind == reflect.Array) && type_.Elem().Kind() != reflect.Uint8 && !field.attr &&
+		ref.Value != nil && ref.Value.Items != nil {
+		items := xmlSchemaRef(ref.Value.Items, type_.Elem(), visiting)
+		if field.name != name {
+			items = withXMLName(items, field.name, false)
+		}
+		if items == ref.Value.Items {
+			return ref
+		}
+		copy := *ref.Value
+		copy.Items = items
+		return openapi3.NewSchemaRef("", &copy)
+	}
+	property := xmlSchemaRef(ref, type_, visiting)
+	if field.name != name || field.attr {
+		property = withXMLName(property, field.name, field.attr)
+	}
+	return property
+}
+
+func withXMLName(ref *openapi3.SchemaRef, name string, attr bool) *openapi3.SchemaRef {
+	names := &openapi3.XML{Name: name, Attribute: attr}
+	if ref.Ref != "" {
+		return openapi3.NewSchemaRef("", &openapi3.Schema{AllOf: openapi3.SchemaRefs{ref}, XML: names})
+	}
+	copy := *ref.Value
+	copy.XML = names
+	return openapi3.NewSchemaRef("", &copy)
+}
+
+// xmlFields maps the names of the properties documented for the fields of type_, from their
+// form or json tags, to the way encoding/xml reads them
+func xmlFields(type_ reflect.Type, fields map[string]xmlField) {
+	for i := 0; i < type_.NumField(); i++ {
+		field := type_.Field(i)
+		xmlTag, hasXMLTag := field.Tag.Lookup("xml")
+		name, options, _ := strings.Cut(xmlTag, ",")
+		property, documented := propertyName(field)
+		if field.Anonymous && !hasXMLTag && property == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				xmlFields(embedded, fields)
+			}
+			continue
+		}
+		if !field.IsExported() || !documented {
+			continue
+		}
+		if property == "" {
+			property = field.Name
+		}
+		if name == "-" && options == "" || field.Type == xmlNameType {
+			fields[property] = xmlField{ignored: true}
+			continue
+		}
+		// character data, inner XML and paths like a>b have no equivalent in the schema
+		if strings.Contains(name, ">") || hasOption(options, "chardata", "cdata", "innerxml", "comment", "any") {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[property] = xmlField{
+			type_: field.Type,
+			name:  name,
+			attr:  hasOption(options, "attr"),
+		}
+	}
+}
+
+// propertyName returns the name of the property documented for field and whether it is part
+// of the body, fields bound from parameters aren't unless they have a form or json tag
+func propertyName(field reflect.StructField) (string, bool) {
+	for _, key := range []string{constants.FORM, constants.JSON} {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			return name, name != "-"
+		}
+	}
+	for _, key := range []string{constants.QUERY, constants.URI, constants.HEADER, constants.COOKIE} {
+		if _, ok := field.Tag.Lookup(key); ok {
+			return "", false
+		}
+	}
+	return "", true
+}
+
+// xmlRootName is the name of the element encoding/xml writes for type_
+func xmlRootName(type_ reflect.Type) string {
+	if type_.Kind() != reflect.Struct {
+		return ""
+	}
+	if field, ok := type_.FieldByName("XMLName"); ok && field.Type == xmlNameType {
+		name, _, _ := strings.Cut(field.Tag.Get("xml"), ",")
+		if name != "" && !strings.Contains(name, " ") {
+			return name
+		}
+	}
+	if strings.ContainsAny(type_.Name(), "[]") {
+		return ""
+	}
+	return type_.Name()
+}
+
+// hasOption reports whether the options of a tag have any of names
+func hasOption(options string, names ...string) bool {
+	for _, option := range strings.Split(options, ",") {
+		for _, name := range names {
+			if option == name {
+				return true
+			}
+		}
+	}
+	return false
+}
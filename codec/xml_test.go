@@ -0,0 +1,130 @@
+package codec
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type xmlAddress struct {
+	City string `json:"city"`
+}
+
+type xmlBase struct {
+	ID int `json:"id" xml:"id,attr"`
+}
+
+type xmlUser struct {
+	xmlBase
+	XMLName xml.Name     `json:"-" xml:"user"`
+	Name    string       `json:"name"`
+	Email   string       `json:"email" xml:"mail"`
+	Tags    []string     `json:"tags" xml:"tag"`
+	Address *xmlAddress  `json:"address"`
+	Secret  string       `json:"secret" xml:"-"`
+	Same    string       `json:"Same"`
+	Token   string       `query:"token"`
+	Friends []*xmlFriend `json:"friends"`
+}
+
+type xmlFriend struct {
+	Name   string     `json:"name"`
+	Friend *xmlFriend `json:"friend"`
+}
+
+func TestXMLSchema(t *testing.T) {
+	address := openapi3.NewObjectSchema().WithProperty("city", openapi3.NewStringSchema())
+	addressRef := openapi3.NewSchemaRef("#/components/schemas/xmlAddress", address)
+	friend := openapi3.NewObjectSchema().WithProperty("name", openapi3.NewStringSchema())
+	friendRef := openapi3.NewSchemaRef("#/components/schemas/xmlFriend", friend)
+	friend.Properties["friend"] = friendRef
+	schema := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewIntegerSchema()).
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("email", openapi3.NewStringSchema()).
+		WithProperty("tags", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithPropertyRef("address", addressRef).
+		WithProperty("secret", openapi3.NewStringSchema()).
+		WithProperty("Same", openapi3.NewStringSchema()).
+		WithProperty("friends", openapi3.NewArraySchema().WithItems(friend))
+	schema.Properties["friends"].Value.Items = friendRef
+	schema.Required = []string{"name", "secret"}
+
+	got := (&XML{}).Schema(schema, reflect.TypeOf(&xmlUser{}))
+	if got == schema {
+		t.Fatal("schema not copied")
+	}
+	if got.XML == nil || got.XML.Name != "user" {
+		t.Errorf("root xml = %+v, want user", got.XML)
+	}
+	tests := []struct {
+		property string
+		xml      *openapi3.XML
+	}{
+		{"id", &openapi3.XML{Name: "id", Attribute: true}},
+		{"name", &openapi3.XML{Name: "Name"}},
+		{"email", &openapi3.XML{Name: "mail"}},
+		{"Same", nil},
+	}
+	for _, test := range tests {
+		property := got.Properties[test.property]
+		if property == nil {
+			t.Errorf("%s: missing", test.property)
+			continue
+		}
+		if !reflect.DeepEqual(property.Value.XML, test.xml) {
+			t.Errorf("%s: xml = %+v, want %+v", test.property, property.Value.XML, test.xml)
+		}
+	}
+	if items := got.Properties["tags"].Value.Items.Value; items.XML == nil || items.XML.Name != "tag" {
+		t.Errorf("tags: items xml = %+v, want tag", items.XML)
+	}
+	if _, ok := got.Properties["secret"]; ok {
+		t.Error("secret: ignored by encoding/xml but documented")
+	}
+	if !reflect.DeepEqual(got.Required, []string{"name"}) {
+		t.Errorf("required = %v, want [name]", got.Required)
+	}
+
+	// components whose properties are renamed are inlined, and keep their json names
+	addressProperty := got.Properties["address"]
+	if addressProperty.Ref != "" || addressProperty.Value.XML == nil || addressProperty.Value.XML.Name != "Address" {
+		t.Fatalf("address = %+v, want an inlined schema named Address", addressProperty.Value)
+	}
+	if city := addressProperty.Value.Properties["city"].Value; city.XML == nil || city.XML.Name != "City" {
+		t.Errorf("address.city: xml = %+v, want City", city.XML)
+	}
+	if address.Properties["city"].Value.XML != nil || schema.Properties["name"].Value.XML != nil {
+		t.Error("schemas of other content types changed")
+	}
+
+	// recursive types end at a reference to the component being named, wrapped to name it
+	friendItems := got.Properties["friends"].Value.Items
+	if friendItems.Value.XML == nil || friendItems.Value.XML.Name != "Friends" {
+		t.Fatalf("friends: items xml = %+v, want Friends", friendItems.Value.XML)
+	}
+	inner := friendItems.Value.Properties["friend"].Value
+	if inner.XML == nil || inner.XML.Name != "Friend" || inner.AllOf[0] != friendRef {
+		t.Errorf("friends.friend = %+v, want a reference to the component named Friend", inner)
+	}
+}
+
+func TestXMLSchemaBindsDocumentedNames(t *testing.T) {
+	var user xmlUser
+	body := `<user id="1"><Name>a</Name><mail>b</mail><tag>c</tag><tag>d</tag><Address><City>e</City></Address></user>`
+	if err := (&XML{}).Decode([]byte(body), &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 1 || user.Name != "a" || user.Email != "b" || len(user.Tags) != 2 || user.Address.City != "e" {
+		t.Errorf("decoded %+v", user)
+	}
+}
+
+func TestXMLSchemaWithoutModel(t *testing.T) {
+	schema := openapi3.NewObjectSchema()
+	if got := (&XML{}).Schema(schema, nil); got != schema {
+		t.Error("schema without model changed")
+	}
+}
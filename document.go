@@ -0,0 +1,129 @@
+package fibers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/swagger"
+	"gopkg.in/yaml.v3"
+)
+
+// MIMEApplicationYAML is the content type of the document served as YAML
+const MIMEApplicationYAML = "application/yaml"
+
+// document is the OpenAPI document serialized once when the app is initialized
+type document struct {
+	json         []byte
+	yaml         []byte
+	etag         string
+	lastModified string
+}
+
+func newDocument(s *swagger.Swagger) (*document, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(s); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return &document{
+		json:         data,
+		yaml:         buf.Bytes(),
+		etag:         hex.EncodeToString(sum[:8]),
+		lastModified: time.Now().UTC().Format(http.TimeFormat),
+	}, nil
+}
+
+// yamlTypes are the content types clients ask for YAML with
+var yamlTypes = []string{MIMEApplicationYAML, "application/x-yaml", "text/yaml"}
+
+// negotiate serves the document as YAML if the Accept header prefers it, and as JSON otherwise
+func (d *document) negotiate(c *fiber.Ctx) error {
+	c.Vary(fiber.HeaderAccept)
+	offer := accepted(c.Get(fiber.HeaderAccept), append([]string{fiber.MIMEApplicationJSON}, yamlTypes...))
+	for _, type_ := range yamlTypes {
+		if offer == type_ {
+			return d.send(c, MIMEApplicationYAML)
+		}
+	}
+	return d.send(c, fiber.MIMEApplicationJSON)
+}
+
+// accepted returns the offer with the highest quality in the Accept header, the first one in
+// the header among equals, fiber's Accepts ignores the quality
+func accepted(header string, offers []string) string {
+	best, bestQuality := "", 0.0
+	for _, spec := range strings.Split(header, ",") {
+		params := strings.Split(spec, ";")
+		mediaType, quality := strings.ToLower(strings.TrimSpace(params[0])), 1.0
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= bestQuality {
+			continue
+		}
+		for _, offer := range offers {
+			prefix, _, _ := strings.Cut(offer, "/")
+			if mediaType == offer || mediaType == "*/*" || mediaType == prefix+"/*" {
+				best, bestQuality = offer, quality
+				break
+			}
+		}
+	}
+	return best
+}
+
+// send writes the document as JSON or YAML, or 304 if the client has it already
+func (d *document) send(c *fiber.Ctx, contentType string) error {
+	body, etag := d.json, `"`+d.etag+`-json"`
+	if contentType == MIMEApplicationYAML {
+		body, etag = d.yaml, `"`+d.etag+`-yaml"`
+	}
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, d.lastModified)
+	if d.fresh(c, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(body)
+}
+
+// fresh reports whether the client has the document tagged etag already, If-None-Match
+// takes precedence over If-Modified-Since, which fiber's Fresh doesn't compare without it
+func (d *document) fresh(c *fiber.Ctx, etag string) bool {
+	if strings.Contains(strings.ToLower(c.Get(fiber.HeaderCacheControl)), "no-cache") {
+		return false
+	}
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, tag := range strings.Split(noneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	modifiedSince, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(d.lastModified)
+	return err == nil && !lastModified.After(modifiedSince)
+}
@@ -0,0 +1,175 @@
+package fibers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+	"github.com/long2ice/fibers/swagger"
+	"gopkg.in/yaml.v3"
+)
+
+func documentApp(t *testing.T) *App {
+	t.Helper()
+	app := New(swagger.New("test", "test", "1.0.0"), fiber.Config{})
+	app.Get("/users", router.NewR(func(c *fiber.Ctx, req testUserReq) (testUser, error) {
+		return testUser{Name: req.Name}, nil
+	}))
+	if err := app.Init(); err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+// get requests path with headers and returns the response with its body
+func get(t *testing.T, app *App, path string, headers map[string]string) (*http.Response, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := app.App.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, body
+}
+
+func TestDocumentNegotiation(t *testing.T) {
+	app := documentApp(t)
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		contentType string
+	}{
+		{"no accept", "/openapi.json", "", fiber.MIMEApplicationJSON},
+		{"json", "/openapi.json", fiber.MIMEApplicationJSON, fiber.MIMEApplicationJSON},
+		{"yaml", "/openapi.json", MIMEApplicationYAML, MIMEApplicationYAML},
+		{"x-yaml", "/openapi.json", "application/x-yaml", MIMEApplicationYAML},
+		{"text yaml", "/openapi.json", "text/yaml", MIMEApplicationYAML},
+		{"json preferred", "/openapi.json", "application/yaml;q=0.5, application/json", fiber.MIMEApplicationJSON},
+		{"yaml preferred", "/openapi.json", "application/json;q=0.5, application/yaml", MIMEApplicationYAML},
+		{"unknown", "/openapi.json", "text/html", fiber.MIMEApplicationJSON},
+		{"any", "/openapi.json", "*/*", fiber.MIMEApplicationJSON},
+		{"any text", "/openapi.json", "text/html, text/*", MIMEApplicationYAML},
+		{"yaml refused", "/openapi.json", "application/yaml;q=0", fiber.MIMEApplicationJSON},
+		{"yaml endpoint", "/openapi.yaml", "", MIMEApplicationYAML},
+		{"yaml endpoint with json accepted", "/openapi.yaml", fiber.MIMEApplicationJSON, MIMEApplicationYAML},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, body := get(t, app, test.path, map[string]string{fiber.HeaderAccept: test.accept})
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("got %d: %s", resp.StatusCode, body)
+			}
+			if contentType := resp.Header.Get(fiber.HeaderContentType); contentType != test.contentType {
+				t.Errorf("got %s, want %s", contentType, test.contentType)
+			}
+			var doc map[string]interface{}
+			if test.contentType == MIMEApplicationYAML {
+				if err := yaml.Unmarshal(body, &doc); err != nil {
+					t.Fatal(err)
+				}
+			} else if err := json.Unmarshal(body, &doc); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := doc["paths"].(map[string]interface{})["/users"]; !ok {
+				t.Errorf("no /users path in %s", body)
+			}
+		})
+	}
+	resp, _ := get(t, app, "/openapi.json", nil)
+	if vary := resp.Header.Get(fiber.HeaderVary); vary != fiber.HeaderAccept {
+		t.Errorf("got Vary %q, want Accept", vary)
+	}
+}
+
+// the YAML document is the JSON document in another format
+func TestDocumentYAML(t *testing.T) {
+	app := documentApp(t)
+	_, data := get(t, app, "/openapi.json", nil)
+	_, yamlData := get(t, app, "/openapi.yaml", nil)
+	var fromJSON, fromYAML interface{}
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(yamlData, &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	// YAML numbers decode as int, so compare both documents as JSON
+	converted, err := json.Marshal(fromYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromConverted interface{}
+	if err = json.Unmarshal(converted, &fromConverted); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, fromConverted) {
+		t.Errorf("got %s\nwant %s", converted, data)
+	}
+}
+
+func TestDocumentCaching(t *testing.T) {
+	app := documentApp(t)
+	jsonResp, _ := get(t, app, "/openapi.json", nil)
+	yamlResp, _ := get(t, app, "/openapi.yaml", nil)
+	etag, lastModified := jsonResp.Header.Get(fiber.HeaderETag), jsonResp.Header.Get(fiber.HeaderLastModified)
+	if etag == "" || lastModified == "" {
+		t.Fatalf("got ETag %q and Last-Modified %q", etag, lastModified)
+	}
+	if _, err := http.ParseTime(lastModified); err != nil {
+		t.Errorf("Last-Modified: %v", err)
+	}
+	yamlETag := yamlResp.Header.Get(fiber.HeaderETag)
+	if yamlETag == "" || yamlETag == etag {
+		t.Errorf("got YAML ETag %q and JSON ETag %q, want different tags", yamlETag, etag)
+	}
+	// the document doesn't change between requests
+	if resp, _ := get(t, app, "/openapi.json", nil); resp.Header.Get(fiber.HeaderETag) != etag {
+		t.Errorf("ETag changed to %q from %q", resp.Header.Get(fiber.HeaderETag), etag)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		status  int
+	}{
+		{"matching etag", "/openapi.json", map[string]string{fiber.HeaderIfNoneMatch: etag}, fiber.StatusNotModified},
+		{"matching yaml etag", "/openapi.yaml", map[string]string{fiber.HeaderIfNoneMatch: yamlETag}, fiber.StatusNotModified},
+		{"etag of the other format", "/openapi.yaml", map[string]string{fiber.HeaderIfNoneMatch: etag}, fiber.StatusOK},
+		{"weak etag in a list", "/openapi.json", map[string]string{fiber.HeaderIfNoneMatch: `"stale", W/` + etag}, fiber.StatusNotModified},
+		{"any etag", "/openapi.json", map[string]string{fiber.HeaderIfNoneMatch: "*"}, fiber.StatusNotModified},
+		{"stale etag", "/openapi.json", map[string]string{fiber.HeaderIfNoneMatch: `"stale"`}, fiber.StatusOK},
+		{"not modified since", "/openapi.json", map[string]string{fiber.HeaderIfModifiedSince: lastModified}, fiber.StatusNotModified},
+		{"modified since", "/openapi.json", map[string]string{fiber.HeaderIfModifiedSince: "Mon, 02 Jan 2006 15:04:05 GMT"}, fiber.StatusOK},
+		{"stale etag and not modified since", "/openapi.json", map[string]string{fiber.HeaderIfNoneMatch: `"stale"`, fiber.HeaderIfModifiedSince: lastModified}, fiber.StatusOK},
+		{"no cache", "/openapi.json", map[string]string{fiber.HeaderIfNoneMatch: etag, fiber.HeaderCacheControl: "no-cache"}, fiber.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, body := get(t, app, test.path, test.headers)
+			if resp.StatusCode != test.status {
+				t.Fatalf("got %d, want %d", resp.StatusCode, test.status)
+			}
+			if test.status == fiber.StatusNotModified && len(body) > 0 {
+				t.Errorf("got body %s with 304", body)
+			}
+			if test.status == fiber.StatusOK && len(body) == 0 {
+				t.Error("got no body")
+			}
+		})
+	}
+}
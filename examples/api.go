@@ -67,8 +67,8 @@ type TestJsonReq struct {
 	Enum string `           validate:"required,oneof=1 2" json:"enum" description:"enum of model" example:"1"`
 }
 
-func TestJson(c *fiber.Ctx, req TestJsonReq) error {
-	return c.JSON(req)
+func TestJson(c *fiber.Ctx, req TestJsonReq) (TestJsonReq, error) {
+	return req, nil
 }
 
 func TestNoModel(c *fiber.Ctx) error {
@@ -1,8 +1,8 @@
 package main
 
 import (
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
 	"github.com/long2ice/fibers/security"
 	log "github.com/sirupsen/logrus"
 
@@ -18,9 +18,6 @@ func main() {
 		if e, ok := err.(*fiber.Error); ok {
 			code = e.Code
 		}
-		if _, ok := err.(validator.ValidationErrors); ok {
-			code = fiber.StatusBadRequest
-		}
 		err = c.Status(code).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -29,6 +26,7 @@ func main() {
 		}
 		return nil
 	}})
+	app.ErrorMode(router.ErrorModeStructured)
 	app.Use(
 		logger.New(),
 		recover.New(),
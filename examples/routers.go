@@ -57,14 +57,9 @@ var (
 		router.Summary("Test form"),
 		router.ContentType(fiber.MIMEApplicationForm, router.ContentTypeRequest),
 	)
-	body = router.New(
+	body = router.NewR(
 		TestJson,
 		router.Summary("Test json body"),
-		router.Responses(router.Response{
-			"200": router.ResponseItem{
-				Model: TestFormReq{},
-			},
-		}),
 	)
 	file = router.New(
 		TestFile,
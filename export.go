@@ -0,0 +1,33 @@
+package fibers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Export runs the BeforeInit function and Init like Listen without binding a port, then
+// writes the OpenAPI document of the app to w as json or yaml. The fibers export command
+// calls it on the app returned by a function of the package given to it.
+func (g *App) Export(w io.Writer, format string) error {
+	if g.Swagger == nil {
+		return errors.New("the app has no swagger docs")
+	}
+	if g.beforeInitFunc != nil && !g.initialized {
+		g.beforeInitFunc()
+	}
+	if err := g.Init(); err != nil {
+		return err
+	}
+	var data []byte
+	switch format {
+	case "json":
+		data = append(append([]byte(nil), g.document.json...), '\n')
+	case "yaml", "yml":
+		data = g.document.yaml
+	default:
+		return fmt.Errorf("unknown format %q, expected json or yaml", format)
+	}
+	_, err := w.Write(data)
+	return err
+}
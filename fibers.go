@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	log "github.com/sirupsen/logrus"
 	"net/http"
+	"sort"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/template/html"
+	"github.com/long2ice/fibers/codec"
 	"github.com/long2ice/fibers/router"
 	"github.com/long2ice/fibers/swagger"
 )
@@ -18,25 +20,43 @@ var templates embed.FS
 
 type App struct {
 	*fiber.App
-	Swagger        *swagger.Swagger
-	Routers        map[string]map[string]*router.Router
-	subApps        map[string]*App
-	rootPath       string
-	beforeInitFunc func()
-	afterInitFunc  func()
+	Swagger            *swagger.Swagger
+	Routers            map[string]map[string]*router.Router
+	Codecs             *codec.Registry
+	Validator          *router.Validator
+	subApps            map[string]*App
+	rootPath           string
+	beforeInitFunc     func()
+	afterInitFunc      func()
+	errorMode          router.ErrorMode
+	responseValidation ResponseValidation
+	requestValidation  bool
+	envelope           *router.Envelope
+	typeSchemas        []typeSchema
+	document           *document
+	strict             bool
+	initialized        bool
+	initErr            error
 }
 
 func New(swagger *swagger.Swagger, config fiber.Config) *App {
 	engine := html.NewFileSystem(http.FS(templates), ".html")
 	config.Views = engine
 	f := &App{
-		App:     fiber.New(config),
-		Swagger: swagger,
-		Routers: make(map[string]map[string]*router.Router),
-		subApps: make(map[string]*App),
+		App:       fiber.New(config),
+		Swagger:   swagger,
+		Routers:   make(map[string]map[string]*router.Router),
+		Codecs:    codec.NewRegistry(),
+		Validator: router.NewValidator(),
+		subApps:   make(map[string]*App),
 	}
+	f.Codecs.Register(fiber.MIMEApplicationJSON, &codec.JSON{
+		Marshal:   f.App.Config().JSONEncoder,
+		Unmarshal: f.App.Config().JSONDecoder,
+	})
 	if swagger != nil {
 		swagger.Routers = f.Routers
+		swagger.Validator = f.Validator
 	}
 	return f
 }
@@ -98,13 +118,19 @@ func (g *App) Options(path string, router *router.Router) {
 	g.Handle(path, fiber.MethodOptions, router)
 }
 
-func (g *App) init() {
+func (g *App) init() error {
 	if g.Swagger == nil {
-		return
+		return nil
 	}
+	g.registerSchemas(g.Swagger)
 	g.App.Get(g.fullPath(g.Swagger.OpenAPIUrl), func(c *fiber.Ctx) error {
-		return c.JSON(g.Swagger)
+		return g.document.negotiate(c)
 	})
+	if g.Swagger.OpenAPIYAMLUrl != "" {
+		g.App.Get(g.fullPath(g.Swagger.OpenAPIYAMLUrl), func(c *fiber.Ctx) error {
+			return g.document.send(c, MIMEApplicationYAML)
+		})
+	}
 	g.App.Get(g.fullPath(g.Swagger.DocsUrl), func(c *fiber.Ctx) error {
 		options := `{}`
 		if g.Swagger.SwaggerOptions != nil {
@@ -136,14 +162,36 @@ func (g *App) init() {
 		})
 	})
 	g.initRouters()
-	g.Swagger.BuildOpenAPI()
+	if err := g.Swagger.BuildOpenAPI(); err != nil {
+		return err
+	}
+	document, err := newDocument(g.Swagger)
+	if err != nil {
+		return err
+	}
+	g.document = document
+	return nil
 }
 
 func (g *App) initRouters() {
 	for path, m := range g.Routers {
 		path = g.fullPath(path)
 		for method, r := range m {
+			r.ErrorMode = g.errorMode
+			r.Codecs = g.Codecs
+			r.Validator = g.Validator
+			// handlers of other routers write their responses themselves
+			if r.Envelope == nil && r.WritesResponse() {
+				r.Envelope = g.envelope
+			}
+			r.RequestValidation = g.requestValidation && g.Swagger != nil && !r.Exclude
 			handlers := r.GetHandlers()
+			if r.RequestValidation {
+				handlers = append([]fiber.Handler{g.requestValidator(r)}, handlers...)
+			}
+			if g.responseValidation != ResponseValidationOff && g.Swagger != nil && !r.Exclude {
+				handlers = append([]fiber.Handler{g.responseValidator(r)}, handlers...)
+			}
 			if method == fiber.MethodGet {
 				g.App.Get(path, handlers...)
 			} else if method == fiber.MethodPost {
@@ -169,11 +217,55 @@ func (g *App) fullPath(path string) string {
 	return g.rootPath + path
 }
 
-func (g *App) Init() {
-	g.init()
-	for _, s := range g.subApps {
-		s.init()
+// Init registers the routers and builds and validates the OpenAPI documents of the app and
+// its sub apps, returning every problem found as swagger.Errors, or panicking with them in
+// strict mode. The app is initialized once, later calls return the same result.
+func (g *App) Init() error {
+	if !g.initialized {
+		g.initialized = true
+		g.initErr = g.initApps()
+	}
+	if g.initErr != nil && g.strict {
+		panic(g.initErr)
+	}
+	return g.initErr
+}
+
+func (g *App) initApps() error {
+	var errs swagger.Errors
+	// errors of sub apps are reported with the path they are mounted at
+	addError := func(err error, rootPath string) {
+		e, ok := err.(swagger.Errors)
+		if !ok {
+			if err != nil {
+				errs = append(errs, err)
+			}
+			return
+		}
+		for _, err := range e {
+			if routeErr, ok := err.(*swagger.RouteError); ok {
+				err = &swagger.RouteError{Path: rootPath + routeErr.Path, Method: routeErr.Method, Err: routeErr.Err}
+			}
+			errs = append(errs, err)
+		}
+	}
+	addError(g.init(), "")
+	paths := make([]string, 0, len(g.subApps))
+	for path := range g.subApps {
+		paths = append(paths, path)
 	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		// schemas registered with RegisterSchema of the sub app are registered after and take precedence
+		if subApp := g.subApps[path]; subApp.Swagger != nil {
+			g.registerSchemas(subApp.Swagger)
+		}
+		addError(g.subApps[path].init(), path)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
 }
 func (g *App) BeforeInit(f func()) {
 	g.beforeInitFunc = f
@@ -181,11 +273,50 @@ func (g *App) BeforeInit(f func()) {
 func (g *App) AfterInit(f func()) {
 	g.afterInitFunc = f
 }
+
+// Strict makes Init and Listen panic with the problems found in the OpenAPI documents
+// instead of returning them
+func (g *App) Strict(enabled bool) {
+	g.strict = enabled
+}
+
+// ErrorMode set how binding and validation failures of all routers are reported
+func (g *App) ErrorMode(mode router.ErrorMode) {
+	g.errorMode = mode
+}
+
+// Envelope wraps the 2xx responses of all routers made with router.NewR, template is a value of
+// the wrapping type with a types.Placeholder field standing for the response model of each
+// router, and wrap builds the body written for the value returned by their handlers
+func (g *App) Envelope(template interface{}, wrap func(c *fiber.Ctx, body interface{}) interface{}) {
+	g.envelope = &router.Envelope{Template: template, Wrap: wrap}
+}
+
+// typeSchema is a schema registered with App.RegisterSchema
+type typeSchema struct {
+	value  interface{}
+	schema *openapi3.Schema
+}
+
+// RegisterSchema documents the type of value with schema in the docs of the app and of the apps
+// mounted on it, for third-party types that can't implement swagger.SchemaProvider
+func (g *App) RegisterSchema(value interface{}, schema *openapi3.Schema) {
+	g.typeSchemas = append(g.typeSchemas, typeSchema{value: value, schema: schema})
+}
+
+func (g *App) registerSchemas(s *swagger.Swagger) {
+	for _, typeSchema := range g.typeSchemas {
+		s.RegisterSchema(typeSchema.value, typeSchema.schema)
+	}
+}
+
 func (g *App) Listen(addr string) error {
 	if g.beforeInitFunc != nil {
 		g.beforeInitFunc()
 	}
-	g.Init()
+	if err := g.Init(); err != nil {
+		return err
+	}
 	if g.afterInitFunc != nil {
 		g.afterInitFunc()
 	}
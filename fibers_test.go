@@ -0,0 +1,108 @@
+package fibers
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+	"github.com/long2ice/fibers/swagger"
+	"github.com/long2ice/fibers/types"
+)
+
+type testUser struct {
+	Name string `json:"name"`
+}
+
+type testEnvelope[T any] struct {
+	Data T `json:"data"`
+}
+
+type testUserReq struct {
+	Name string `query:"name"`
+}
+
+func TestEnvelope(t *testing.T) {
+	app := New(swagger.New("test", "test", "1.0.0"), fiber.Config{})
+	app.Envelope(testEnvelope[types.Placeholder]{}, func(c *fiber.Ctx, body interface{}) interface{} {
+		return testEnvelope[interface{}]{Data: body}
+	})
+	app.ValidateResponses(ResponseValidationFail)
+	app.Get("/typed", router.NewR(func(c *fiber.Ctx, req testUserReq) (testUser, error) {
+		return testUser{Name: req.Name}, nil
+	}))
+	app.Get("/plain", router.New(func(c *fiber.Ctx, req testUserReq) error {
+		return c.JSON(testUser{Name: req.Name})
+	}, router.Responses(router.Response{"200": router.ResponseItem{Model: testUser{}, Description: "user"}})))
+	app.Get("/excluded", router.NewR(func(c *fiber.Ctx, req testUserReq) (testUser, error) {
+		return testUser{Name: req.Name}, nil
+	}, router.NoEnvelope()))
+	if err := app.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		ref  string
+		body string
+	}{
+		{"/typed", "#/components/schemas/testEnvelope_testUser", `{"data":{"name":"a"}}`},
+		{"/plain", "#/components/schemas/testUser", `{"name":"a"}`},
+		{"/excluded", "#/components/schemas/testUser", `{"name":"a"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			response := app.Swagger.Operation(test.path, fiber.MethodGet).Responses.Get(200).Value
+			if ref := response.Content.Get(fiber.MIMEApplicationJSON).Schema.Ref; ref != test.ref {
+				t.Errorf("documented %s, want %s", ref, test.ref)
+			}
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, test.path+"?name=a", nil), -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != fiber.StatusOK || string(body) != test.body {
+				t.Errorf("got %d %s, want 200 %s", resp.StatusCode, body, test.body)
+			}
+		})
+	}
+}
+
+// testDecimal stands for a type of another package
+type testDecimal struct {
+	value []byte
+}
+
+type testPrice struct {
+	Amount testDecimal `json:"amount"`
+}
+
+func TestRegisterSchema(t *testing.T) {
+	app := New(swagger.New("test", "test", "1.0.0"), fiber.Config{})
+	app.RegisterSchema(testDecimal{}, openapi3.NewStringSchema().WithFormat("decimal"))
+	app.Post("/prices", router.New(func(c *fiber.Ctx, req testPrice) error { return nil }))
+	sub := New(swagger.New("sub", "sub", "1.0.0"), fiber.Config{})
+	sub.Post("/prices", router.New(func(c *fiber.Ctx, req testPrice) error { return nil }))
+	app.Mount("/sub", sub)
+	overriding := New(swagger.New("overriding", "overriding", "1.0.0"), fiber.Config{})
+	overriding.RegisterSchema(testDecimal{}, openapi3.NewStringSchema().WithFormat("money"))
+	overriding.Post("/prices", router.New(func(c *fiber.Ctx, req testPrice) error { return nil }))
+	app.Mount("/overriding", overriding)
+	if err := app.Init(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		app    *App
+		format string
+	}{{app, "decimal"}, {sub, "decimal"}, {overriding, "money"}}
+	for _, test := range tests {
+		amount := test.app.Swagger.OpenAPI.Components.Schemas["testPrice"].Value.Properties["amount"].Value
+		if amount.Type != "string" || amount.Format != test.format {
+			t.Errorf("%s: amount is %s %s, want a string of format %s",
+				test.app.Swagger.Title, amount.Type, amount.Format, test.format)
+		}
+	}
+}
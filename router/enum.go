@@ -0,0 +1,83 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/long2ice/fibers/types"
+)
+
+// EnumError reports a bound value outside the set of its enum type
+type EnumError struct {
+	// Namespace locates the field like validator.FieldError.StructNamespace
+	Namespace string
+	Value     interface{}
+	Values    []interface{}
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("%s must be one of %v", e.Namespace, e.Values)
+}
+
+// validateEnums checks the enum values of model, zero values are left to the required rule
+func validateEnums(value reflect.Value, namespace string) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if values, ok := types.EnumValues(value.Type()); ok && value.Kind() != reflect.Struct {
+		if value.IsZero() {
+			return nil
+		}
+		if !containsEnumValue(values, value.Interface()) {
+			return &EnumError{Namespace: namespace, Value: value.Interface(), Values: values}
+		}
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if field := value.Type().Field(i); field.IsExported() {
+				if err := validateEnums(value.Field(i), namespace+"."+field.Name); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := validateEnums(value.Index(i), fmt.Sprintf("%s[%d]", namespace, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if err := validateEnums(iter.Value(), fmt.Sprintf("%s[%v]", namespace, iter.Key())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// containsEnumValue reports whether values has value, comparing them as they are written
+// in JSON as values may be of the enum type or of its underlying type
+func containsEnumValue(values []interface{}, value interface{}) bool {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+		if other, err := json.Marshal(v); err == nil && bytes.Equal(data, other) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type enumStatus string
+
+// Values returns plain strings rather than values of the type
+func (enumStatus) Values() []interface{} {
+	return []interface{}{"active", "inactive"}
+}
+
+type enumLevel int
+
+const (
+	enumLow enumLevel = iota + 1
+	enumHigh
+)
+
+// Values has a pointer receiver
+func (*enumLevel) Values() []interface{} {
+	return []interface{}{enumLow, enumHigh}
+}
+
+type enumReq struct {
+	Status  enumStatus   `json:"status"`
+	Level   *enumLevel   `json:"level"`
+	History []enumStatus `json:"history"`
+}
+
+func TestValidateEnums(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"plain values", `{"status":"active"}`, fiber.StatusOK},
+		{"value outside plain values", `{"status":"deleted"}`, fiber.StatusUnprocessableEntity},
+		{"pointer receiver", `{"level":2}`, fiber.StatusOK},
+		{"value outside pointer receiver", `{"level":3}`, fiber.StatusUnprocessableEntity},
+		{"slice", `{"history":["active","inactive"]}`, fiber.StatusOK},
+		{"value outside slice", `{"history":["active","deleted"]}`, fiber.StatusUnprocessableEntity},
+		{"zero values", `{}`, fiber.StatusOK},
+	}
+	for _, mode := range []ErrorMode{ErrorModeRaw, ErrorModeStructured} {
+		app := fiber.New()
+		r := New(func(c *fiber.Ctx, req enumReq) error {
+			return c.SendString("ok")
+		})
+		r.ErrorMode = mode
+		app.Post("/", r.GetHandlers()...)
+		for _, test := range tests {
+			req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader(test.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.code {
+				t.Errorf("mode %d, %s: got %d, want %d", mode, test.name, resp.StatusCode, test.code)
+			}
+		}
+	}
+}
+
+func TestEnumErrorField(t *testing.T) {
+	level := enumLevel(3)
+	err := NewValidationError(LocationBody, &enumReq{}, validateEnums(
+		reflect.ValueOf(&enumReq{History: []enumStatus{"active", "deleted"}, Level: &level}), "enumReq"))
+	if len(err.Errors) != 1 || err.Errors[0].Field != "level" || err.Errors[0].Rule != "enum" {
+		t.Errorf("got %+v, want the enum rule of level", err.Errors)
+	}
+}
@@ -0,0 +1,11 @@
+package router
+
+import "github.com/gofiber/fiber/v2"
+
+// Envelope wraps the 2xx responses of routers. Template is a value of the wrapping type with
+// a types.Placeholder field that documents the response model, and Wrap builds the body
+// written for the value returned by the handlers of NewR.
+type Envelope struct {
+	Template interface{}
+	Wrap     func(c *fiber.Ctx, body interface{}) interface{}
+}
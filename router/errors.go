@@ -0,0 +1,283 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/constants"
+	"github.com/long2ice/fibers/types"
+)
+
+// ErrorMode decides how binding and validation failures are reported
+type ErrorMode int
+
+const (
+	// ErrorModeRaw passes the underlying error on to fiber's ErrorHandler
+	ErrorModeRaw ErrorMode = iota
+	// ErrorModeStructured responds with a ValidationError body
+	ErrorModeStructured
+)
+
+// LocationBody is the location of fields bound from the request body
+const LocationBody = "body"
+
+type FieldError struct {
+	Location string `json:"location" validate:"required,oneof=query header uri cookie body" description:"where the field was read from"`
+	Field    string `json:"field"                        description:"name of the field from its struct tag"`
+	Rule     string `json:"rule"     validate:"required" description:"rule that failed"`
+	Message  string `json:"message"  validate:"required" description:"human readable message"`
+}
+
+type ValidationError struct {
+	Code   int          `json:"-"`
+	Errors []FieldError `json:"errors" validate:"required"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, item := range e.Errors {
+		messages[i] = item.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// NewValidationError converts an error returned while binding model from location
+// into a ValidationError, 422 for failed validation rules and 400 otherwise.
+func NewValidationError(location string, model interface{}, err error) *ValidationError {
+	return DefaultValidator.NewValidationError(location, model, err)
+}
+
+// NewValidationError is like the package level NewValidationError with messages
+// translated by the validator.
+func (v *Validator) NewValidationError(location string, model interface{}, err error) *ValidationError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		ret := &ValidationError{Code: fiber.StatusUnprocessableEntity}
+		for _, fe := range validationErrors {
+			location, field := locateField(reflect.TypeOf(model), fe.StructNamespace())
+			ret.Errors = append(ret.Errors, FieldError{
+				Location: location,
+				Field:    field,
+				Rule:     fe.Tag(),
+				Message:  v.message(field, fe),
+			})
+		}
+		return ret
+	}
+	var enumError *EnumError
+	if errors.As(err, &enumError) {
+		location, field := locateField(reflect.TypeOf(model), enumError.Namespace)
+		return &ValidationError{Code: fiber.StatusUnprocessableEntity, Errors: []FieldError{{
+			Location: location,
+			Field:    field,
+			Rule:     "enum",
+			Message:  fmt.Sprintf("%s must be one of %v", field, enumError.Values),
+		}}}
+	}
+	ret := &ValidationError{Code: fiber.StatusBadRequest}
+	var typeError *json.UnmarshalTypeError
+	var syntaxError *json.SyntaxError
+	var fiberError *fiber.Error
+	if errors.As(err, &typeError) {
+		ret.Errors = append(ret.Errors, FieldError{
+			Location: location,
+			Field:    typeError.Field,
+			Rule:     "type",
+			Message:  fmt.Sprintf("expected %s but got %s", JSONType(typeError.Type), typeError.Value),
+		})
+	} else if errors.As(err, &syntaxError) {
+		ret.Errors = append(ret.Errors, FieldError{
+			Location: location,
+			Rule:     "syntax",
+			Message:  syntaxError.Error(),
+		})
+	} else if errors.As(err, &fiberError) {
+		ret.Code = fiberError.Code
+		ret.Errors = append(ret.Errors, FieldError{
+			Location: location,
+			Rule:     "content-type",
+			Message:  fiberError.Message,
+		})
+	} else if value := conversionErrors(err); value.IsValid() {
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			ret.Errors = append(ret.Errors, FieldError{
+				Location: location,
+				Field:    key.String(),
+				Rule:     "type",
+				Message:  fmt.Sprint(value.MapIndex(key).Interface()),
+			})
+		}
+	} else {
+		ret.Errors = append(ret.Errors, FieldError{
+			Location: location,
+			Rule:     "parse",
+			Message:  err.Error(),
+		})
+	}
+	return ret
+}
+
+// conversionErrors returns the map of key to error fiber's schema decoder reports conversion
+// failures as, which fiber wraps
+func conversionErrors(err error) reflect.Value {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if value := reflect.ValueOf(err); value.Kind() == reflect.Map {
+			return value
+		}
+	}
+	return reflect.Value{}
+}
+
+// JSONType names the JSON type the values of a Go type are written as
+func JSONType(type_ reflect.Type) string {
+	for type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	switch type_.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return type_.String()
+}
+
+// variantType returns the type of the variant that has the first of the remaining fields
+func variantType(polymorphic types.Polymorphic, parts []string) reflect.Type {
+	_, variants := polymorphic.Variants()
+	for _, variant := range variants {
+		type_ := reflect.TypeOf(variant.Type)
+		for type_.Kind() == reflect.Ptr {
+			type_ = type_.Elem()
+		}
+		if len(parts) == 0 || type_.Kind() != reflect.Struct {
+			return type_
+		}
+		name, _, _ := strings.Cut(parts[0], "[")
+		if _, ok := type_.FieldByName(name); ok {
+			return type_
+		}
+	}
+	return reflect.TypeOf(polymorphic)
+}
+
+func validationMessage(field string, fe validator.FieldError) string {
+	rule := fe.Tag()
+	if fe.Param() != "" {
+		rule += "=" + fe.Param()
+	}
+	return fmt.Sprintf("%s failed on the '%s' rule", field, rule)
+}
+
+// locateField walks a validator struct namespace such as TestQueryReq.TokenHeader.Token
+// and returns where the field is bound from along with its tag name.
+func locateField(type_ reflect.Type, namespace string) (string, string) {
+	location := ""
+	var names []string
+	parts := strings.Split(namespace, ".")
+	for pos, part := range parts[1:] {
+		for type_.Kind() == reflect.Ptr {
+			type_ = type_.Elem()
+		}
+		name, index := part, ""
+		if i := strings.Index(part, "["); i >= 0 {
+			name, index = part[:i], part[i:]
+		}
+		// the value of a types.OneOf is the variant holding the next field
+		if polymorphic, ok := reflect.New(type_).Elem().Interface().(types.Polymorphic); ok && name == "Value" {
+			type_ = variantType(polymorphic, parts[pos+2:])
+			continue
+		}
+		if type_.Kind() != reflect.Struct {
+			names = append(names, part)
+			continue
+		}
+		field, ok := type_.FieldByName(name)
+		if !ok {
+			names = append(names, part)
+			continue
+		}
+		type_ = field.Type
+		for n := strings.Count(index, "["); n > 0; n-- {
+			for type_.Kind() == reflect.Ptr {
+				type_ = type_.Elem()
+			}
+			if kind := type_.Kind(); kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map {
+				type_ = type_.Elem()
+			}
+		}
+		if isEmbeddedField(field) && index == "" {
+			continue
+		}
+		tagName := ""
+		if location == "" {
+			for _, tag := range []string{constants.QUERY, constants.URI, constants.HEADER, constants.COOKIE} {
+				if tagName = tagValue(field, tag); tagName != "" {
+					location = tag
+					break
+				}
+			}
+			if location == "" {
+				location = LocationBody
+			}
+		}
+		if tagName == "" {
+			tagName = tagValue(field, constants.FORM)
+		}
+		if tagName == "" {
+			tagName = tagValue(field, constants.JSON)
+		}
+		if tagName == "" {
+			tagName = field.Name
+		}
+		names = append(names, tagName+index)
+	}
+	if location == "" {
+		location = LocationBody
+	}
+	return location, strings.Join(names, ".")
+}
+
+// isEmbeddedField reports whether the fields of field are promoted to the struct holding it
+func isEmbeddedField(field reflect.StructField) bool {
+	if _, ok := field.Tag.Lookup(constants.EMBED); ok {
+		return true
+	}
+	for _, key := range []string{constants.FORM, constants.JSON} {
+		options := strings.Split(field.Tag.Get(key), ",")
+		if options[0] != "" {
+			return false
+		}
+		for _, option := range options[1:] {
+			if option == "inline" {
+				return true
+			}
+		}
+	}
+	return field.Anonymous
+}
+
+func tagValue(field reflect.StructField, key string) string {
+	name := strings.Split(field.Tag.Get(key), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,118 @@
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type errorsAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type errorsReq struct {
+	Page    int             `query:"page"`
+	Token   string          `header:"X-Token" validate:"required"`
+	Name    string          `json:"name" validate:"min=2"`
+	Age     int             `json:"age"`
+	Address []errorsAddress `json:"addresses" validate:"dive"`
+}
+
+func TestValidationErrorBody(t *testing.T) {
+	app := fiber.New()
+	r := New(func(c *fiber.Ctx, req errorsReq) error {
+		return c.SendString("ok")
+	})
+	r.ErrorMode = ErrorModeStructured
+	app.Post("/", r.GetHandlers()...)
+	tests := []struct {
+		name        string
+		query       string
+		token       string
+		contentType string
+		body        string
+		code        int
+		want        FieldError
+	}{
+		{"query type", "?page=a", "t", fiber.MIMEApplicationJSON, `{"name":"ab"}`, fiber.StatusBadRequest,
+			FieldError{Location: "query", Field: "page", Rule: "type"}},
+		{"missing header", "", "", fiber.MIMEApplicationJSON, `{"name":"ab"}`, fiber.StatusUnprocessableEntity,
+			FieldError{Location: "header", Field: "X-Token", Rule: "required"}},
+		{"body rule", "", "t", fiber.MIMEApplicationJSON, `{"name":"a"}`, fiber.StatusUnprocessableEntity,
+			FieldError{Location: "body", Field: "name", Rule: "min"}},
+		{"nested body rule", "", "t", fiber.MIMEApplicationJSON, `{"name":"ab","addresses":[{}]}`,
+			fiber.StatusUnprocessableEntity, FieldError{Location: "body", Field: "addresses[0].city", Rule: "required"}},
+		{"body type", "", "t", fiber.MIMEApplicationJSON, `{"name":"ab","age":"a"}`, fiber.StatusBadRequest,
+			FieldError{Location: "body", Field: "age", Rule: "type"}},
+		{"body syntax", "", "t", fiber.MIMEApplicationJSON, `{"name":`, fiber.StatusBadRequest,
+			FieldError{Location: "body", Rule: "syntax"}},
+		{"content type", "", "t", "application/unknown", `name=ab`, fiber.StatusUnprocessableEntity,
+			FieldError{Location: "body", Rule: "content-type"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/"+test.query, strings.NewReader(test.body))
+			req.Header.Set(fiber.HeaderContentType, test.contentType)
+			if test.token != "" {
+				req.Header.Set("X-Token", test.token)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			data, _ := io.ReadAll(resp.Body)
+			var body ValidationError
+			if err = json.Unmarshal(data, &body); err != nil {
+				t.Fatalf("%v: %s", err, data)
+			}
+			if resp.StatusCode != test.code || len(body.Errors) != 1 {
+				t.Fatalf("got %d %s, want %d with one error", resp.StatusCode, data, test.code)
+			}
+			got := body.Errors[0]
+			if got.Message == "" {
+				t.Errorf("no message in %s", data)
+			}
+			got.Message = ""
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := NewValidationError(LocationBody, &errorsReq{}, DefaultValidator.Struct(&errorsReq{Name: "a"}))
+	want := []string{"X-Token failed on the 'required' rule", "name failed on the 'min=2' rule"}
+	for i, item := range err.Errors {
+		if i >= len(want) || item.Message != want[i] {
+			t.Errorf("got %q, want %q", item.Message, want)
+		}
+	}
+}
+
+func TestJSONType(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{errorsReq{}, "object"},
+		{map[string]int{}, "object"},
+		{[]int{}, "array"},
+		{true, "boolean"},
+		{"", "string"},
+		{new(int), "integer"},
+		{uint8(0), "integer"},
+		{0.0, "number"},
+	}
+	for _, test := range tests {
+		if got := JSONType(reflect.TypeOf(test.value)); got != test.want {
+			t.Errorf("JSONType(%T) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
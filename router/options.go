@@ -1,6 +1,8 @@
 package router
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/long2ice/fibers/security"
 )
@@ -81,3 +83,25 @@ func ContentType(contentType string, contentTypeType ContentTypeType) Option {
 		}
 	}
 }
+
+// BindBody turn request body binding and docs on or off whatever the method is
+func BindBody(enabled bool) Option {
+	return func(router *Router) {
+		router.BindBody = &enabled
+	}
+}
+
+// KeepAlive set the interval of keep-alive comments on event streams, which also notice
+// clients that disconnected from idle streams, DefaultKeepAlive if not positive
+func KeepAlive(interval time.Duration) Option {
+	return func(router *Router) {
+		router.KeepAlive = interval
+	}
+}
+
+// NoEnvelope keep the responses of the router out of the envelope of the app
+func NoEnvelope() Option {
+	return func(router *Router) {
+		router.Envelope = &Envelope{}
+	}
+}
@@ -2,11 +2,13 @@ package router
 
 import (
 	"container/list"
+	"fmt"
 	"reflect"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/jinzhu/copier"
+	"github.com/long2ice/fibers/codec"
+	"github.com/long2ice/fibers/constants"
 	"github.com/long2ice/fibers/security"
 	"github.com/mcuadros/go-defaults"
 )
@@ -29,39 +31,171 @@ type Router struct {
 	Exclude             bool
 	Securities          []security.ISecurity
 	Response            Response
+	ErrorMode           ErrorMode
+	RequestValidation   bool
+	BindBody            *bool
+	Codecs              *codec.Registry
+	Validator           *Validator
+	Events              Events
+	KeepAlive           time.Duration
+	Envelope            *Envelope
+	writesResponse      bool
 }
 
-var validate = validator.New()
+// RequestModel is the locals key under which BindModel stores the bound model
+const RequestModel = "requestModel"
 
+// BindModel binds every request into a fresh instance of req's type and stores
+// a pointer to it in c.Locals(RequestModel), so concurrent requests never share
+// state.
 func BindModel(req interface{}) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		model := reflect.New(reflect.TypeOf(req).Elem()).Interface()
-		if err := HeaderParser(c, model); err != nil {
-			return err
-		}
-		if err := CookiesParser(c, model); err != nil {
-			return err
+	router := &Router{Model: req}
+	return router.bindModel
+}
+
+func (router *Router) bindModel(c *fiber.Ctx) error {
+	type_ := reflect.TypeOf(router.Model)
+	if type_ == nil {
+		return fmt.Errorf("%s %s has no model type to bind the request into", c.Method(), c.Route().Path)
+	}
+	if type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	model := reflect.New(type_).Interface()
+	if err := HeaderParser(c, model); err != nil {
+		return router.bindError(c, constants.HEADER, model, err)
+	}
+	if err := CookiesParser(c, model); err != nil {
+		return router.bindError(c, constants.COOKIE, model, err)
+	}
+	if err := c.QueryParser(model); err != nil {
+		return router.bindError(c, constants.QUERY, model, err)
+	}
+	if len(c.Body()) > 0 && router.HasBody(c.Method()) {
+		if err := router.parseBody(c, model); err != nil {
+			return router.bindError(c, LocationBody, model, err)
 		}
-		if err := c.QueryParser(model); err != nil {
-			return err
+	}
+	if err := ParamsParser(c, model); err != nil {
+		return router.bindError(c, constants.URI, model, err)
+	}
+	defaults.SetDefaults(model)
+	if err := router.validator().Struct(model); err != nil {
+		return router.bindError(c, LocationBody, model, err)
+	}
+	if err := validateEnums(reflect.ValueOf(model), type_.Name()); err != nil {
+		if router.ErrorMode != ErrorModeStructured {
+			// a value outside the enum is a client error, which fiber's ErrorHandler only knows from a fiber.Error
+			return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
 		}
-		if c.Method() == fiber.MethodPost || c.Method() == fiber.MethodPut {
-			if err := c.BodyParser(model); err != nil {
-				return err
+		return router.bindError(c, LocationBody, model, err)
+	}
+	c.Locals(RequestModel, model)
+	return c.Next()
+}
+
+// requestModel returns the model bound by bindModel as T, which is the pointer stored if T
+// is a pointer and the value it points to otherwise
+func requestModel[T Model](c *fiber.Ctx) T {
+	model := c.Locals(RequestModel)
+	if req, ok := model.(T); ok {
+		return req
+	}
+	return *model.(*T)
+}
+
+// Codec returns the codec registered for contentType, JSON if it is empty
+func (router *Router) Codec(contentType string) (codec.Codec, bool) {
+	if contentType == "" {
+		contentType = fiber.MIMEApplicationJSON
+	}
+	registry := router.Codecs
+	if registry == nil {
+		registry = codec.Default
+	}
+	return registry.Get(contentType)
+}
+
+func (router *Router) validator() *Validator {
+	if router.Validator == nil {
+		return DefaultValidator
+	}
+	return router.Validator
+}
+
+// parseBody decodes the body with the codec of its content type, falling back to
+// RequestContentType and then to fiber's BodyParser for forms.
+func (router *Router) parseBody(c *fiber.Ctx, model interface{}) error {
+	contentType := string(c.Request().Header.ContentType())
+	if contentType == "" && router.RequestContentType != "" {
+		contentType = router.RequestContentType
+		c.Request().Header.SetContentType(contentType)
+	}
+	if bodyCodec, ok := router.Codec(contentType); ok {
+		return bodyCodec.Decode(c.Body(), model)
+	}
+	return c.BodyParser(model)
+}
+
+// HasBody reports whether the request body is bound and documented for method,
+// which is true for POST, PUT and PATCH, and for DELETE when the model has fields
+// that are not parameters, unless set by BindBody.
+func (router *Router) HasBody(method string) bool {
+	if router.BindBody != nil {
+		return *router.BindBody
+	}
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		return true
+	case fiber.MethodDelete:
+		return router.Model != nil && hasBodyFields(reflect.TypeOf(router.Model))
+	}
+	return false
+}
+
+// hasBodyFields reports whether a model has fields bound from the body rather than
+// from the query, path, headers or cookies
+func hasBodyFields(type_ reflect.Type) bool {
+	for type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	if type_.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < type_.NumField(); i++ {
+		field := type_.Field(i)
+		if isEmbeddedField(field) {
+			if hasBodyFields(field.Type) {
+				return true
 			}
+			continue
 		}
-		if err := ParamsParser(c, model); err != nil {
-			return err
+		if !field.IsExported() || isParameterField(field) {
+			continue
 		}
-		defaults.SetDefaults(model)
-		if err := validate.Struct(model); err != nil {
-			return err
+		if field.Tag.Get(constants.FORM) == "-" || field.Tag.Get(constants.JSON) == "-" {
+			continue
 		}
-		if err := copier.Copy(req, model); err != nil {
-			return err
+		return true
+	}
+	return false
+}
+
+func isParameterField(field reflect.StructField) bool {
+	for _, key := range []string{constants.QUERY, constants.URI, constants.HEADER, constants.COOKIE} {
+		if _, ok := field.Tag.Lookup(key); ok {
+			return true
 		}
-		return c.Next()
 	}
+	return false
+}
+
+func (router *Router) bindError(c *fiber.Ctx, location string, model interface{}, err error) error {
+	if router.ErrorMode != ErrorModeStructured {
+		return err
+	}
+	validationError := router.validator().NewValidationError(location, model, err)
+	return c.Status(validationError.Code).JSON(validationError)
 }
 
 func (router *Router) GetHandlers() []fiber.Handler {
@@ -92,12 +226,11 @@ func NewX(f fiber.Handler, options ...Option) *Router {
 }
 func New[T Model, F func(c *fiber.Ctx, req T) error](f F, options ...Option) *Router {
 	var model T
-	h := BindModel(&model)
 	r := &Router{
 		Handlers: list.New(),
 		Response: make(Response),
 		API: func(ctx *fiber.Ctx) error {
-			return f(ctx, model)
+			return f(ctx, requestModel[T](ctx))
 		},
 		Model: model,
 	}
@@ -105,10 +238,79 @@ func New[T Model, F func(c *fiber.Ctx, req T) error](f F, options ...Option) *Ro
 		option(r)
 	}
 
-	r.Handlers.PushBack(h)
+	r.Handlers.PushBack(fiber.Handler(r.bindModel))
+	return r
+}
+
+// NewR creates a router whose handler returns the response model, which is
+// serialized with the response content type and documented as the 200 response.
+func NewR[T Model, R any, F func(c *fiber.Ctx, req T) (R, error)](f F, options ...Option) *Router {
+	var model T
+	var response R
+	r := &Router{
+		Handlers:       list.New(),
+		Response:       make(Response),
+		Model:          model,
+		writesResponse: true,
+	}
+	r.API = func(ctx *fiber.Ctx) error {
+		ret, err := f(ctx, requestModel[T](ctx))
+		if err != nil {
+			return err
+		}
+		return r.respond(ctx, ret)
+	}
+	for _, option := range options {
+		option(r)
+	}
+	responses := make(Response, len(r.Response)+1)
+	for code, item := range r.Response {
+		responses[code] = item
+	}
+	item := responses["200"]
+	if item.Description == "" {
+		item.Description = "success"
+	}
+	item.Model = response
+	responses["200"] = item
+	r.Response = responses
+
+	r.Handlers.PushBack(fiber.Handler(r.bindModel))
 	return r
 }
 
+// WritesResponse reports whether the router writes the value returned by its handler, as
+// routers made with NewR do, which is what an envelope can wrap.
+func (router *Router) WritesResponse() bool {
+	return router.writesResponse
+}
+
+// respond serializes body with the codec of the response content type
+func (router *Router) respond(c *fiber.Ctx, body interface{}) error {
+	contentType := router.ResponseContentType
+	if contentType == "" {
+		contentType = fiber.MIMEApplicationJSON
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	if router.Envelope != nil && router.Envelope.Wrap != nil {
+		body = router.Envelope.Wrap(c, body)
+	}
+	if bodyCodec, ok := router.Codec(contentType); ok {
+		data, err := bodyCodec.Encode(body)
+		if err != nil {
+			return err
+		}
+		return c.Send(data)
+	}
+	switch body := body.(type) {
+	case string:
+		return c.SendString(body)
+	case []byte:
+		return c.Send(body)
+	}
+	return fmt.Errorf("no codec to serialize %T as %s", body, contentType)
+}
+
 func (router *Router) WithSecurity(securities ...security.ISecurity) *Router {
 	Security(securities...)(router)
 	return router
@@ -158,3 +360,18 @@ func (router *Router) WithContentType(contentType string, contentTypeType Conten
 	ContentType(contentType, contentTypeType)(router)
 	return router
 }
+
+func (router *Router) WithBindBody(enabled bool) *Router {
+	BindBody(enabled)(router)
+	return router
+}
+
+func (router *Router) WithKeepAlive(interval time.Duration) *Router {
+	KeepAlive(interval)(router)
+	return router
+}
+
+func (router *Router) WithNoEnvelope() *Router {
+	NoEnvelope()(router)
+	return router
+}
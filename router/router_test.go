@@ -0,0 +1,167 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type concurrentReq struct {
+	ID   int      `uri:"id"`
+	Name string   `query:"name"`
+	Tags []string `query:"tags"`
+}
+
+type concurrentResp struct {
+	ID   int      `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func TestBindModelConcurrent(t *testing.T) {
+	tests := []struct {
+		name   string
+		router *Router
+	}{
+		{"New with value model", New(func(c *fiber.Ctx, req concurrentReq) error {
+			return c.JSON(concurrentResp{ID: req.ID, Name: req.Name, Tags: req.Tags})
+		})},
+		{"New with pointer model", New(func(c *fiber.Ctx, req *concurrentReq) error {
+			return c.JSON(concurrentResp{ID: req.ID, Name: req.Name, Tags: req.Tags})
+		})},
+		{"NewR with value model", NewR(func(c *fiber.Ctx, req concurrentReq) (concurrentResp, error) {
+			return concurrentResp{ID: req.ID, Name: req.Name, Tags: req.Tags}, nil
+		})},
+		{"NewR with pointer model", NewR(func(c *fiber.Ctx, req *concurrentReq) (concurrentResp, error) {
+			return concurrentResp{ID: req.ID, Name: req.Name, Tags: req.Tags}, nil
+		})},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testConcurrent(t, test.router)
+		})
+	}
+}
+
+// testConcurrent sends requests with different parameters to r at once and checks that each
+// response has the parameters of its own request
+func testConcurrent(t *testing.T, r *Router) {
+	app := fiber.New()
+	app.Get("/users/:id", r.GetHandlers()...)
+
+	const requests = 200
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// only even requests send tags, so a model shared between requests would leak them
+			url := fmt.Sprintf("/users/%d?name=user%d", i, i)
+			if i%2 == 0 {
+				url += fmt.Sprintf("&tags=t%d", i)
+			}
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, url, nil), -1)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				errs <- err
+				return
+			}
+			var got concurrentResp
+			if err = json.Unmarshal(body, &got); err != nil {
+				errs <- fmt.Errorf("request %d: %w: %s", i, err, body)
+				return
+			}
+			if got.ID != i || got.Name != fmt.Sprintf("user%d", i) {
+				errs <- fmt.Errorf("request %d: got %+v", i, got)
+				return
+			}
+			if i%2 == 0 && (len(got.Tags) != 1 || got.Tags[0] != fmt.Sprintf("t%d", i)) || i%2 == 1 && len(got.Tags) != 0 {
+				errs <- fmt.Errorf("request %d: got tags %v", i, got.Tags)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestBindModelWithoutType(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", New(func(c *fiber.Ctx, req any) error {
+		return c.SendString("bound")
+	}).GetHandlers()...)
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("got %d, want 500", resp.StatusCode)
+	}
+}
+
+type bodyHeader struct {
+	Token string `header:"token"`
+}
+
+func TestHasBody(t *testing.T) {
+	type paramsOnly struct {
+		bodyHeader `embed:""`
+		ID         int    `uri:"id" json:"id"`
+		Name       string `query:"name" json:"name"`
+	}
+	type withBody struct {
+		ID     int    `uri:"id"`
+		Reason string `json:"reason"`
+	}
+	type embeddedBody struct {
+		bodyHeader `embed:""`
+		withBody
+	}
+	type ignored struct {
+		ID     int    `uri:"id"`
+		Secret string `json:"-"`
+	}
+	enabled, disabled := true, false
+	tests := []struct {
+		name     string
+		model    Model
+		method   string
+		bindBody *bool
+		want     bool
+	}{
+		{"post", paramsOnly{}, fiber.MethodPost, nil, true},
+		{"patch", withBody{}, fiber.MethodPatch, nil, true},
+		{"get", withBody{}, fiber.MethodGet, nil, false},
+		{"delete with parameters only", paramsOnly{}, fiber.MethodDelete, nil, false},
+		{"delete with body fields", withBody{}, fiber.MethodDelete, nil, true},
+		{"delete with embedded body fields", &embeddedBody{}, fiber.MethodDelete, nil, true},
+		{"delete with ignored fields", ignored{}, fiber.MethodDelete, nil, false},
+		{"delete without model", nil, fiber.MethodDelete, nil, false},
+		{"delete with slice", []withBody{}, fiber.MethodDelete, nil, true},
+		{"bind body on", paramsOnly{}, fiber.MethodDelete, &enabled, true},
+		{"bind body off", withBody{}, fiber.MethodPost, &disabled, false},
+		{"bind body on get", withBody{}, fiber.MethodGet, &enabled, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &Router{Model: test.model, BindBody: test.bindBody}
+			if got := r.HasBody(test.method); got != test.want {
+				t.Errorf("HasBody(%s) = %v, want %v", test.method, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package router
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+const MIMETextEventStream = "text/event-stream"
+
+// DefaultKeepAlive is the interval of keep-alive comments sent on idle streams
+const DefaultKeepAlive = 15 * time.Second
+
+// Events documents the events a stream sends, keyed by event name
+type Events map[string]EventItem
+
+type EventItem struct {
+	Description string
+	Model       interface{}
+}
+
+// Event is a server-sent event, Data is encoded as JSON
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// StreamFunc sends events to stream until it returns or the client disconnects
+type StreamFunc func(stream *Stream) error
+
+// Stream writes server-sent events to a client
+type Stream struct {
+	// LastEventID is the Last-Event-ID header sent by a reconnecting client
+	LastEventID string
+	router      *Router
+	writer      *bufio.Writer
+	mutex       sync.Mutex
+	ctx         context.Context
+	cancel      context.CancelFunc
+	nextID      int64
+	// keepingAlive is done once the keep-alive goroutine has stopped writing
+	keepingAlive sync.WaitGroup
+}
+
+func newStream(router *Router, writer *bufio.Writer, lastEventID string) *Stream {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &Stream{
+		LastEventID: lastEventID,
+		router:      router,
+		writer:      writer,
+		ctx:         ctx,
+		cancel:      cancel,
+		nextID:      1,
+	}
+	if id, err := strconv.ParseInt(lastEventID, 10, 64); err == nil {
+		stream.nextID = id + 1
+	}
+	return stream
+}
+
+// Context is done once the client disconnects or the stream ends
+func (stream *Stream) Context() context.Context {
+	return stream.ctx
+}
+
+// Send sends data as a declared event with the next numeric id
+func (stream *Stream) Send(event string, data interface{}) error {
+	if err := stream.check(Event{Event: event, Data: data}); err != nil {
+		return err
+	}
+	stream.mutex.Lock()
+	id := stream.nextID
+	stream.nextID++
+	stream.mutex.Unlock()
+	return stream.send(Event{ID: strconv.FormatInt(id, 10), Event: event, Data: data})
+}
+
+// SendEvent sends an event, returning an error if the client has disconnected or
+// the event doesn't match the declared ones.
+func (stream *Stream) SendEvent(event Event) error {
+	if err := stream.check(event); err != nil {
+		return err
+	}
+	return stream.send(event)
+}
+
+// send writes an event that has been checked
+func (stream *Stream) send(event Event) error {
+	bodyCodec, _ := stream.router.Codec(fiber.MIMEApplicationJSON)
+	data, err := bodyCodec.Encode(event.Data)
+	if err != nil {
+		return err
+	}
+	var builder strings.Builder
+	if event.ID != "" {
+		builder.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Event != "" {
+		builder.WriteString("event: " + event.Event + "\n")
+	}
+	if event.Retry > 0 {
+		builder.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		builder.WriteString("data: " + line + "\n")
+	}
+	builder.WriteString("\n")
+
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	if id, err := strconv.ParseInt(event.ID, 10, 64); err == nil && id >= stream.nextID {
+		stream.nextID = id + 1
+	}
+	return stream.write(builder.String())
+}
+
+// check makes sure event is declared with the type of its data
+func (stream *Stream) check(event Event) error {
+	if len(stream.router.Events) == 0 {
+		return nil
+	}
+	item, ok := stream.router.Events[event.Event]
+	if !ok {
+		return fmt.Errorf("event %q is not declared", event.Event)
+	}
+	if item.Model == nil || event.Data == nil {
+		return nil
+	}
+	expected, actual := reflect.TypeOf(item.Model), reflect.TypeOf(event.Data)
+	for expected.Kind() == reflect.Ptr {
+		expected = expected.Elem()
+	}
+	for actual.Kind() == reflect.Ptr {
+		actual = actual.Elem()
+	}
+	if expected != actual {
+		return fmt.Errorf("event %q expects %s but got %s", event.Event, expected, actual)
+	}
+	return nil
+}
+
+// write must be called with the mutex held
+func (stream *Stream) write(s string) error {
+	if err := stream.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := stream.writer.WriteString(s); err != nil {
+		stream.cancel()
+		return err
+	}
+	if err := stream.writer.Flush(); err != nil {
+		stream.cancel()
+		return err
+	}
+	return nil
+}
+
+// serve runs streamFunc with keep-alive comments sent every interval, and returns once
+// nothing writes to the stream anymore so that its writer can be released. Errors of
+// streams whose client disconnected are not returned.
+func (stream *Stream) serve(streamFunc StreamFunc, interval time.Duration) error {
+	defer stream.close()
+	if interval <= 0 {
+		interval = DefaultKeepAlive
+	}
+	stream.keepingAlive.Add(1)
+	go stream.keepAlive(interval)
+	if err := streamFunc(stream); err != nil && stream.ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// close ends the stream and waits for the keep-alive goroutine, canceling under the mutex
+// so that no write starts after it
+func (stream *Stream) close() {
+	stream.mutex.Lock()
+	stream.cancel()
+	stream.mutex.Unlock()
+	stream.keepingAlive.Wait()
+}
+
+// keepAlive sends comments every interval so proxies don't close idle streams, which is
+// also how a client that disconnected while no event is sent is noticed
+func (stream *Stream) keepAlive(interval time.Duration) {
+	defer stream.keepingAlive.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.ctx.Done():
+			return
+		case <-ticker.C:
+			stream.mutex.Lock()
+			_ = stream.write(": keep-alive\n\n")
+			stream.mutex.Unlock()
+		}
+	}
+}
+
+// NewSSE creates a router streaming server-sent events, f runs before the stream
+// starts so it can still reject the request, then the StreamFunc it returns sends
+// the events declared in events.
+func NewSSE[T Model, F func(c *fiber.Ctx, req T) (StreamFunc, error)](
+	f F,
+	events Events,
+	options ...Option,
+) *Router {
+	var model T
+	r := &Router{
+		Handlers:            list.New(),
+		Response:            make(Response),
+		Model:               model,
+		Events:              events,
+		KeepAlive:           DefaultKeepAlive,
+		ResponseContentType: MIMETextEventStream,
+	}
+	r.API = func(ctx *fiber.Ctx) error {
+		streamFunc, err := f(ctx, requestModel[T](ctx))
+		if err != nil {
+			return err
+		}
+		lastEventID := ctx.Get("Last-Event-ID")
+		ctx.Set(fiber.HeaderContentType, MIMETextEventStream)
+		ctx.Set(fiber.HeaderCacheControl, "no-cache")
+		ctx.Set(fiber.HeaderConnection, "keep-alive")
+		ctx.Set("X-Accel-Buffering", "no")
+		ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			stream := newStream(r, w, lastEventID)
+			if err := stream.serve(streamFunc, r.KeepAlive); err != nil {
+				log.Errorf("event stream of %s %s: %v", r.Method, r.Path, err)
+			}
+		})
+		return nil
+	}
+	for _, option := range options {
+		option(r)
+	}
+	if _, ok := r.Response["200"]; !ok {
+		responses := make(Response, len(r.Response)+1)
+		for code, item := range r.Response {
+			responses[code] = item
+		}
+		responses["200"] = ResponseItem{Description: "event stream"}
+		r.Response = responses
+	}
+
+	r.Handlers.PushBack(fiber.Handler(r.bindModel))
+	return r
+}
@@ -0,0 +1,167 @@
+package router
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type progress struct {
+	Percent int `json:"percent"`
+}
+
+type streamReq struct {
+	Steps int `query:"steps"`
+}
+
+// stream requests path of a router streaming f and returns the body
+func stream(t *testing.T, f StreamFunc, headers map[string]string, options ...Option) string {
+	t.Helper()
+	app := fiber.New()
+	r := NewSSE(func(c *fiber.Ctx, req streamReq) (StreamFunc, error) {
+		return f, nil
+	}, Events{"progress": {Model: progress{}}, "done": {}}, options...)
+	app.Get("/", r.GetHandlers()...)
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get(fiber.HeaderContentType); contentType != MIMETextEventStream {
+		t.Errorf("content type %s", contentType)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestSSEFraming(t *testing.T) {
+	body := stream(t, func(stream *Stream) error {
+		if err := stream.Send("progress", progress{Percent: 50}); err != nil {
+			return err
+		}
+		return stream.SendEvent(Event{ID: "10", Event: "done", Data: "ok", Retry: 3 * time.Second})
+	}, nil)
+	want := "id: 1\nevent: progress\ndata: {\"percent\":50}\n\n" +
+		"id: 10\nevent: done\nretry: 3000\ndata: \"ok\"\n\n"
+	if body != want {
+		t.Errorf("got %q, want %q", body, want)
+	}
+}
+
+func TestSSEUndeclaredEvents(t *testing.T) {
+	var errs []error
+	stream(t, func(stream *Stream) error {
+		errs = append(errs, stream.Send("other", progress{}), stream.Send("progress", streamReq{}))
+		return nil
+	}, nil)
+	for _, err := range errs {
+		if err == nil {
+			t.Error("no error")
+		}
+	}
+}
+
+func TestSSELastEventID(t *testing.T) {
+	var lastEventID string
+	body := stream(t, func(stream *Stream) error {
+		lastEventID = stream.LastEventID
+		for i := 0; i < 2; i++ {
+			if err := stream.Send("progress", progress{Percent: i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, map[string]string{"Last-Event-ID": "5"})
+	if lastEventID != "5" {
+		t.Errorf("LastEventID = %q", lastEventID)
+	}
+	if !strings.HasPrefix(body, "id: 6\n") || !strings.Contains(body, "id: 7\n") {
+		t.Errorf("got %q, want ids 6 and 7", body)
+	}
+}
+
+func TestSSEKeepAlive(t *testing.T) {
+	body := stream(t, func(stream *Stream) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}, nil, KeepAlive(5*time.Millisecond))
+	if !strings.HasPrefix(body, ": keep-alive\n\n") {
+		t.Errorf("got %q, want keep-alive comments", body)
+	}
+}
+
+// disconnectedWriter fails every write like the connection of a client that went away,
+// and reports writes made after the stream was closed
+type disconnectedWriter struct {
+	closed  int32
+	written int32
+	late    int32
+}
+
+func (w *disconnectedWriter) Write(p []byte) (int, error) {
+	atomic.AddInt32(&w.written, 1)
+	if atomic.LoadInt32(&w.closed) == 1 {
+		atomic.AddInt32(&w.late, 1)
+	}
+	return 0, errors.New("connection reset")
+}
+
+func TestSSEDisconnect(t *testing.T) {
+	w := &disconnectedWriter{}
+	s := newStream(&Router{}, bufio.NewWriter(w), "")
+	done := make(chan error, 1)
+	go func() {
+		done <- s.serve(func(stream *Stream) error {
+			// nothing is sent, the keep-alive comments notice the client is gone
+			select {
+			case <-stream.Context().Done():
+				return stream.Send("progress", progress{})
+			case <-time.After(5 * time.Second):
+				return errors.New("disconnect not noticed")
+			}
+		}, time.Millisecond)
+	}()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&w.closed, 1)
+	time.Sleep(10 * time.Millisecond)
+	if atomic.LoadInt32(&w.written) == 0 {
+		t.Error("nothing written")
+	}
+	if late := atomic.LoadInt32(&w.late); late > 0 {
+		t.Errorf("%d writes after the stream was closed", late)
+	}
+}
+
+func TestSSENoWriteAfterClose(t *testing.T) {
+	var buffer strings.Builder
+	s := newStream(&Router{}, bufio.NewWriter(&buffer), "")
+	err := s.serve(func(stream *Stream) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}, time.Microsecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the race detector reports keep-alive comments written while the buffer is read
+	length := buffer.Len()
+	time.Sleep(10 * time.Millisecond)
+	if buffer.Len() != length {
+		t.Error("keep-alive comment written after the stream was closed")
+	}
+}
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Constraint applies the OpenAPI keywords implied by a validation rule to schema,
+// param is the rule's text after "=".
+type Constraint func(schema *openapi3.Schema, param string)
+
+// Validator validates bound models, it embeds validator.Validate so struct level
+// validations and tag name functions can be registered directly.
+type Validator struct {
+	*validator.Validate
+	translator  ut.Translator
+	constraints map[string]Constraint
+}
+
+// DefaultValidator validates models of routers not mounted on an App
+var DefaultValidator = NewValidator()
+
+func NewValidator() *Validator {
+	return &Validator{
+		Validate:    validator.New(),
+		constraints: make(map[string]Constraint),
+	}
+}
+
+// RegisterRule registers a validation rule with the constraint it adds to the docs, which may be nil
+func (v *Validator) RegisterRule(tag string, fn validator.Func, constraint Constraint) error {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	if constraint != nil {
+		v.constraints[tag] = constraint
+	}
+	return nil
+}
+
+// Constraint returns the constraint registered for a rule
+func (v *Validator) Constraint(tag string) (Constraint, bool) {
+	constraint, ok := v.constraints[tag]
+	return constraint, ok
+}
+
+// SetTranslator translates error messages with trans, register functions such as
+// en_translations.RegisterDefaultTranslations add the messages of built-in rules.
+func (v *Validator) SetTranslator(
+	trans ut.Translator,
+	register ...func(v *validator.Validate, trans ut.Translator) error,
+) error {
+	for _, r := range register {
+		if err := r(v.Validate, trans); err != nil {
+			return err
+		}
+	}
+	v.translator = trans
+	return nil
+}
+
+// RegisterTranslation registers the message of a rule for the current translator,
+// {0} is replaced with the field name and {1} with the rule's param. SetTranslator
+// must be called first.
+func (v *Validator) RegisterTranslation(tag string, text string) error {
+	if v.translator == nil {
+		return fmt.Errorf("translation of %s registered before SetTranslator", tag)
+	}
+	return v.Validate.RegisterTranslation(tag, v.translator, func(trans ut.Translator) error {
+		return trans.Add(tag, text, true)
+	}, func(trans ut.Translator, fe validator.FieldError) string {
+		message, err := trans.T(tag, fe.Field(), fe.Param())
+		if err != nil {
+			return fe.Error()
+		}
+		return message
+	})
+}
+
+// message returns the translated message of fe if there is one, or a generic one naming field
+func (v *Validator) message(field string, fe validator.FieldError) string {
+	if v.translator != nil {
+		if message := fe.Translate(v.translator); message != fe.Error() {
+			return message
+		}
+	}
+	return validationMessage(field, fe)
+}
@@ -0,0 +1,9 @@
+package router
+
+import "testing"
+
+func TestRegisterTranslationWithoutTranslator(t *testing.T) {
+	if err := NewValidator().RegisterTranslation("even", "{0} must be even"); err == nil {
+		t.Error("RegisterTranslation before SetTranslator succeeded")
+	}
+}
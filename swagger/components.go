@@ -0,0 +1,243 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/long2ice/fibers/types"
+)
+
+const componentsPath = "#/components/schemas/"
+
+// component is the schema of a named type, built once per request or response
+// usage and referenced through $ref everywhere else.
+type component struct {
+	type_    reflect.Type
+	request  bool
+	schema   *openapi3.Schema
+	refs     []*openapi3.SchemaRef
+	children map[reflect.Type]bool
+}
+
+type componentKey struct {
+	type_   reflect.Type
+	request bool
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+	invalidName    = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// isComponentType reports whether type_ is documented as a component, which named
+// structs and enums are
+func isComponentType(type_ reflect.Type) bool {
+	if type_.Name() != "" && types.IsEnum(type_) {
+		return true
+	}
+	return type_.Kind() == reflect.Struct && type_.Name() != "" &&
+		type_ != timeType && type_ != fileHeaderType
+}
+
+// getComponentRef returns a reference to the component of type_, building it on first use
+func (swagger *Swagger) getComponentRef(type_ reflect.Type, request bool) *openapi3.SchemaRef {
+	if n := len(swagger.building); n > 0 {
+		swagger.building[n-1].children[type_] = true
+	}
+	key := componentKey{type_: type_, request: request}
+	c, ok := swagger.components[key]
+	if !ok {
+		// register before building so that references back to the type end the recursion
+		c = &component{
+			type_:    type_,
+			request:  request,
+			schema:   &openapi3.Schema{},
+			children: make(map[reflect.Type]bool),
+		}
+		swagger.components[key] = c
+		swagger.building = append(swagger.building, c)
+		model := reflect.New(type_).Elem().Interface()
+		if request {
+			*c.schema = *swagger.getRequestSchemaByModel(model)
+		} else {
+			*c.schema = *swagger.getResponseSchemaByModel(model)
+		}
+		swagger.building = swagger.building[:len(swagger.building)-1]
+		if values, ok := types.EnumValues(type_); ok {
+			c.schema.Enum = swagger.getEnumValues(type_, values)
+		}
+	}
+	ref := openapi3.NewSchemaRef(componentsPath+swagger.schemaName(type_), c.schema)
+	c.refs = append(c.refs, ref)
+	return ref
+}
+
+// schemaName is the component name of type_ before collisions are resolved
+func (swagger *Swagger) schemaName(type_ reflect.Type) string {
+	if envelope, ok := swagger.envelopes[type_]; ok {
+		return envelope.name
+	}
+	if swagger.SchemaNamer != nil {
+		return swagger.SchemaNamer(type_)
+	}
+	if name, ok := genericName(type_); ok {
+		return name
+	}
+	return invalidName.ReplaceAllString(type_.Name(), "_")
+}
+
+// pkgPath is the package path of type_, or of the template of an envelope
+func (swagger *Swagger) pkgPath(type_ reflect.Type) string {
+	if envelope, ok := swagger.envelopes[type_]; ok {
+		return envelope.template.PkgPath()
+	}
+	return type_.PkgPath()
+}
+
+// schemaNames names every component type, qualifying names shared by instantiations of a generic
+// type with their type arguments, and names shared by other types of different packages with
+// the package name, or the full package path if needed.
+func (swagger *Swagger) schemaNames(types []reflect.Type) map[reflect.Type]string {
+	names := make(map[reflect.Type]string, len(types))
+	byName := make(map[string][]reflect.Type)
+	for _, type_ := range types {
+		name := swagger.schemaName(type_)
+		byName[name] = append(byName[name], type_)
+	}
+	for name, sameName := range byName {
+		if len(sameName) == 1 {
+			names[sameName[0]] = name
+			continue
+		}
+		if qualified, ok := swagger.qualifyArguments(sameName); ok {
+			for type_, name := range qualified {
+				names[type_] = name
+			}
+			continue
+		}
+		qualified := make(map[string]int)
+		for _, type_ := range sameName {
+			qualified[path.Base(swagger.pkgPath(type_))+"."+name]++
+		}
+		for _, type_ := range sameName {
+			names[type_] = path.Base(swagger.pkgPath(type_)) + "." + name
+			if qualified[names[type_]] > 1 {
+				names[type_] = invalidName.ReplaceAllString(swagger.pkgPath(type_), "_") + "." + name
+			}
+		}
+	}
+	// types still sharing a name, like a generic instantiation and a type of the same package
+	// named after it, are told apart by number
+	counts := make(map[string]int)
+	for _, type_ := range types {
+		name := names[type_]
+		if counts[name]++; counts[name] > 1 {
+			names[type_] = fmt.Sprintf("%s_%d", name, counts[name])
+		}
+	}
+	return names
+}
+
+// buildComponents names the components and points their references at them. A type
+// used by both requests and responses shares one component unless the schemas
+// differ, then the request one is suffixed with Input.
+func (swagger *Swagger) buildComponents() openapi3.Schemas {
+	variants := make(map[reflect.Type][2]*component)
+	for _, c := range swagger.components {
+		v := variants[c.type_]
+		if c.request {
+			v[1] = c
+		} else {
+			v[0] = c
+		}
+		variants[c.type_] = v
+	}
+	types := make([]reflect.Type, 0, len(variants))
+	separate := make(map[reflect.Type]bool)
+	for type_, v := range variants {
+		types = append(types, type_)
+		if v[0] != nil && v[1] != nil && !sameSchema(v[0].schema, v[1].schema) {
+			separate[type_] = true
+		}
+	}
+	// schemas referencing separated types differ too
+	for changed := true; changed; {
+		changed = false
+		for type_, v := range variants {
+			if separate[type_] || v[0] == nil || v[1] == nil {
+				continue
+			}
+			for _, c := range v {
+				for child := range c.children {
+					if separate[child] && !separate[type_] {
+						separate[type_] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return swagger.pkgPath(types[i])+"."+types[i].String() < swagger.pkgPath(types[j])+"."+types[j].String()
+	})
+	names := swagger.schemaNames(types)
+	schemas := make(openapi3.Schemas)
+	for _, type_ := range types {
+		for _, c := range variants[type_] {
+			if c == nil {
+				continue
+			}
+			name := names[type_]
+			if c.request && separate[type_] {
+				name += "Input"
+			}
+			if !c.request || variants[type_][0] == nil || separate[type_] {
+				schemas[name] = openapi3.NewSchemaRef("", c.schema)
+			}
+			for _, ref := range c.refs {
+				ref.Ref = componentsPath + name
+			}
+		}
+	}
+	return schemas
+}
+
+// getEnumValues returns the values of an enum as they are written in JSON
+func (swagger *Swagger) getEnumValues(type_ reflect.Type, enum []interface{}) []interface{} {
+	values := make([]interface{}, 0, len(enum))
+	for _, value := range enum {
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(data, &value)
+		}
+		if err != nil {
+			swagger.addError(fmt.Errorf("enum %s: %w", type_, err))
+			continue
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func sameSchema(a *openapi3.Schema, b *openapi3.Schema) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(dataA) == string(dataB)
+}
+
+// inline wraps a component reference in allOf, so keywords can be set next to it
+// as siblings of $ref are ignored.
+func inline(ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	if ref.Ref == "" {
+		return ref
+	}
+	return openapi3.NewSchemaRef("", &openapi3.Schema{AllOf: openapi3.SchemaRefs{ref}})
+}
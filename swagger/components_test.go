@@ -0,0 +1,144 @@
+package swagger
+
+import (
+	"crypto/x509/pkix"
+	"encoding/xml"
+	htmltemplate "html/template"
+	"reflect"
+	"testing"
+	texttemplate "text/template"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+	"github.com/long2ice/fibers/types"
+)
+
+type page[T any] struct {
+	Items []T `json:"items"`
+}
+
+type pair[K any, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+type envelopeTemplate struct {
+	Data types.Placeholder `json:"data"`
+}
+
+type user struct {
+	Name string `json:"name"`
+}
+
+// built returns a swagger without routers whose document has been built, so that schemas can
+// be generated with it
+func built(t *testing.T) *Swagger {
+	t.Helper()
+	swagger := New("test", "test", "1.0.0")
+	if err := swagger.BuildOpenAPI(); err != nil {
+		t.Fatal(err)
+	}
+	return swagger
+}
+
+func TestSchemaNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		types map[reflect.Type]string
+	}{
+		{"unique", map[reflect.Type]string{
+			reflect.TypeOf(page[user]{}): "page_user",
+			reflect.TypeOf(user{}):       "user",
+		}},
+		{"arguments of packages with different names", map[reflect.Type]string{
+			reflect.TypeOf(page[xml.Name]{}):  "page_xml.Name",
+			reflect.TypeOf(page[pkix.Name]{}): "page_pkix.Name",
+		}},
+		{"arguments of packages with the same name", map[reflect.Type]string{
+			reflect.TypeOf(page[texttemplate.Template]{}): "page_text_template.Template",
+			reflect.TypeOf(page[htmltemplate.Template]{}): "page_html_template.Template",
+		}},
+		{"only the arguments telling them apart", map[reflect.Type]string{
+			reflect.TypeOf(pair[xml.Name, user]{}):  "pair_xml.Name_user",
+			reflect.TypeOf(pair[pkix.Name, user]{}): "pair_pkix.Name_user",
+		}},
+		{"nested arguments", map[reflect.Type]string{
+			reflect.TypeOf(page[[]xml.Name]{}):  "page_ListOfxml.Name",
+			reflect.TypeOf(page[[]pkix.Name]{}): "page_ListOfpkix.Name",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			swagger := built(t)
+			types := make([]reflect.Type, 0, len(test.types))
+			for type_ := range test.types {
+				types = append(types, type_)
+			}
+			names := swagger.schemaNames(types)
+			for type_, want := range test.types {
+				if names[type_] != want {
+					t.Errorf("%s: got %s, want %s", type_, names[type_], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaNamesOfEnvelopes(t *testing.T) {
+	swagger := built(t)
+	_, xmlType := swagger.getEnvelopeRef(envelopeTemplate{}, xml.Name{})
+	_, pkixType := swagger.getEnvelopeRef(envelopeTemplate{}, pkix.Name{})
+	_, userType := swagger.getEnvelopeRef(envelopeTemplate{}, user{})
+	names := swagger.schemaNames([]reflect.Type{xmlType, pkixType, userType})
+	want := map[reflect.Type]string{
+		xmlType:  "envelopeTemplate_xml.Name",
+		pkixType: "envelopeTemplate_pkix.Name",
+		userType: "envelopeTemplate_user",
+	}
+	for type_, name := range want {
+		if names[type_] != name {
+			t.Errorf("got %s, want %s", names[type_], name)
+		}
+	}
+}
+
+type node struct {
+	Name     string `json:"name"`
+	Children []node `json:"children"`
+	Parent   *node  `json:"parent"`
+}
+
+func TestSelfReferencingComponent(t *testing.T) {
+	doc := document(t, map[string]*router.Router{
+		"POST /nodes": router.NewR(func(c *fiber.Ctx, req node) (node, error) { return req, nil }),
+	})
+	assertJSON(t, property(t, doc, "node", "children"),
+		`{"type":"array","items":{"$ref":"#/components/schemas/node"}}`)
+	assertJSON(t, property(t, doc, "node", "parent"),
+		`{"allOf":[{"$ref":"#/components/schemas/node"}],"nullable":true}`)
+}
+
+type level int
+
+func (*level) Values() []interface{} {
+	return []interface{}{level(1), level(2)}
+}
+
+type status string
+
+func (status) Values() []interface{} {
+	return []interface{}{"active", "inactive"}
+}
+
+type enumsModel struct {
+	Level  level  `json:"level"`
+	Status status `json:"status"`
+}
+
+func TestEnumComponents(t *testing.T) {
+	doc := document(t, map[string]*router.Router{
+		"POST /enums": router.New(func(c *fiber.Ctx, req enumsModel) error { return nil }),
+	})
+	assertJSON(t, componentSchema(t, doc, "level"), `{"type":"integer","enum":[1,2]}`)
+	assertJSON(t, componentSchema(t, doc, "status"), `{"type":"string","enum":["active","inactive"]}`)
+}
@@ -0,0 +1,346 @@
+package swagger
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/structtag"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/long2ice/fibers/constants"
+	"github.com/long2ice/fibers/router"
+)
+
+// validateFormats maps validator rules to the string format they check
+var validateFormats = map[string]string{
+	"email":            "email",
+	"url":              "uri",
+	"http_url":         "uri",
+	"uri":              "uri",
+	"uuid":             "uuid",
+	"uuid3":            "uuid",
+	"uuid4":            "uuid",
+	"uuid5":            "uuid",
+	"uuid_rfc4122":     "uuid",
+	"uuid3_rfc4122":    "uuid",
+	"uuid4_rfc4122":    "uuid",
+	"uuid5_rfc4122":    "uuid",
+	"ip":               "ip",
+	"ip_addr":          "ip",
+	"ipv4":             "ipv4",
+	"ip4_addr":         "ipv4",
+	"ipv6":             "ipv6",
+	"ip6_addr":         "ipv6",
+	"hostname":         "hostname",
+	"hostname_rfc1123": "hostname",
+	"fqdn":             "hostname",
+	"base64":           "byte",
+}
+
+// validatePatterns maps validator rules to the regular expression they check, as in the validator
+var validatePatterns = map[string]string{
+	"alpha":       `^[a-zA-Z]+$`,
+	"alphanum":    `^[a-zA-Z0-9]+$`,
+	"numeric":     `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"number":      `^[0-9]+$`,
+	"hexadecimal": `^(0[xX])?[0-9a-fA-F]+$`,
+	"hexcolor":    `^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`,
+	"e164":        `^\+[1-9]?[0-9]{7,14}$`,
+}
+
+// datetimeFormats maps datetime layouts to the string format they describe
+var datetimeFormats = map[string]string{
+	time.RFC3339:     "date-time",
+	time.RFC3339Nano: "date-time",
+	"2006-01-02":     "date",
+	"15:04:05":       "time",
+}
+
+// splitParams splits the values of oneof like the validator, keeping quoted values whole
+var splitParams = regexp.MustCompile(`'[^']*'|\S+`)
+
+// applyConstraints adds the keywords implied by validate options to schema, the options
+// after dive apply to the items of arrays and the values of maps.
+func (swagger *Swagger) applyConstraints(schema *openapi3.Schema, options []string) {
+	keys := false
+	for i, option := range options {
+		if keys {
+			keys = option != "endkeys"
+			continue
+		}
+		if option == "keys" {
+			keys = true
+			continue
+		}
+		if option == "dive" {
+			if items := diveSchema(schema); items != nil {
+				swagger.applyConstraints(items, options[i+1:])
+			}
+			return
+		}
+		// alternatives of rules can't be described by single keywords
+		if strings.Contains(option, "|") {
+			continue
+		}
+		name, param, _ := strings.Cut(option, "=")
+		if err := applyConstraint(schema, name, param); err != nil {
+			swagger.addError(fmt.Errorf("validate option %q: %w", option, err))
+		}
+		if constraint, ok := swagger.validator().Constraint(name); ok {
+			constraint(schema, param)
+		}
+	}
+}
+
+// diveSchema returns the schema of the items of an array or the values of a map
+func diveSchema(schema *openapi3.Schema) *openapi3.Schema {
+	if schema.Type == openapi3.TypeArray && schema.Items != nil {
+		schema.Items = inline(schema.Items)
+		return schema.Items.Value
+	}
+	if schema.Type == openapi3.TypeObject && schema.AdditionalProperties.Schema != nil {
+		schema.AdditionalProperties.Schema = inline(schema.AdditionalProperties.Schema)
+		return schema.AdditionalProperties.Schema.Value
+	}
+	return nil
+}
+
+func applyConstraint(schema *openapi3.Schema, name string, param string) error {
+	switch name {
+	case "oneof", "eq":
+		values := []string{param}
+		if name == "oneof" {
+			values = splitParams.FindAllString(param, -1)
+		}
+		enum := make([]interface{}, len(values))
+		for i, value := range values {
+			value, err := parseValue(schema.Type, strings.Trim(value, "'"))
+			if err != nil {
+				return err
+			}
+			enum[i] = value
+		}
+		schema.WithEnum(enum...)
+	case "min", "gte", "gt":
+		return withBound(schema, param, true, name == "gt")
+	case "max", "lte", "lt":
+		return withBound(schema, param, false, name == "lt")
+	case "len":
+		if err := withBound(schema, param, true, false); err != nil {
+			return err
+		}
+		return withBound(schema, param, false, false)
+	case "unique":
+		if schema.Type == openapi3.TypeArray {
+			schema.UniqueItems = true
+		}
+	case "datetime":
+		if format, ok := datetimeFormats[param]; ok && schema.Type == openapi3.TypeString {
+			schema.Format = format
+		}
+	case "startswith":
+		withPattern(schema, "^"+regexp.QuoteMeta(param))
+	case "endswith":
+		withPattern(schema, regexp.QuoteMeta(param)+"$")
+	case "contains":
+		withPattern(schema, regexp.QuoteMeta(param))
+	default:
+		if format, ok := validateFormats[name]; ok && schema.Type == openapi3.TypeString {
+			schema.Format = format
+		}
+		if pattern, ok := validatePatterns[name]; ok {
+			withPattern(schema, pattern)
+		}
+	}
+	return nil
+}
+
+// withBound sets the lower or upper bound of a schema, which is a length for strings, arrays
+// and maps and a value for numbers. Exclusive bounds of lengths are turned into inclusive ones,
+// and lengths have to be integers that are not negative.
+func withBound(schema *openapi3.Schema, param string, lower bool, exclusive bool) error {
+	// bounds of time fields without a parameter are relative to now
+	if param == "" {
+		return nil
+	}
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return err
+	}
+	if schema.Type == openapi3.TypeInteger || schema.Type == openapi3.TypeNumber {
+		if lower {
+			schema.Min = &value
+			schema.ExclusiveMin = exclusive
+		} else {
+			schema.Max = &value
+			schema.ExclusiveMax = exclusive
+		}
+		return nil
+	}
+	if value < 0 || value != math.Trunc(value) {
+		return fmt.Errorf("%s is not a length", param)
+	}
+	length := uint64(value)
+	if exclusive && lower {
+		length++
+	} else if exclusive && length > 0 {
+		length--
+	}
+	switch schema.Type {
+	case openapi3.TypeString:
+		if lower {
+			schema.MinLength = length
+		} else {
+			schema.MaxLength = &length
+		}
+	case openapi3.TypeArray:
+		if lower {
+			schema.MinItems = length
+		} else {
+			schema.MaxItems = &length
+		}
+	case openapi3.TypeObject:
+		if lower {
+			schema.MinProps = length
+		} else {
+			schema.MaxProps = &length
+		}
+	}
+	return nil
+}
+
+// withPattern sets the pattern of a schema, adding further patterns through allOf
+func withPattern(schema *openapi3.Schema, pattern string) {
+	if schema.Type != openapi3.TypeString {
+		return
+	}
+	if schema.Pattern == "" {
+		schema.Pattern = pattern
+		return
+	}
+	schema.AllOf = append(schema.AllOf, openapi3.NewSchemaRef("", &openapi3.Schema{Pattern: pattern}))
+}
+
+// parseValue parses a validate parameter as a value of the schema type, numbers are float64
+// like the ones decoded from JSON so that enum values compare equal to them
+func parseValue(type_ string, value string) (interface{}, error) {
+	switch type_ {
+	case openapi3.TypeInteger:
+		i, err := strconv.ParseInt(value, 10, 64)
+		return float64(i), err
+	case openapi3.TypeNumber:
+		return strconv.ParseFloat(value, 64)
+	case openapi3.TypeBoolean:
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
+
+// getConditionalRequired describes the required_if, required_unless, required_with and
+// required_without rules of a field as anyOf schemas for the object holding it
+func (swagger *Swagger) getConditionalRequired(
+	type_ reflect.Type,
+	name string,
+	options []string,
+) openapi3.SchemaRefs {
+	var schemas openapi3.SchemaRefs
+	required := &openapi3.Schema{Required: []string{name}}
+	anyOf := func(schemas ...*openapi3.Schema) *openapi3.SchemaRef {
+		schema := &openapi3.Schema{}
+		for _, s := range schemas {
+			schema.AnyOf = append(schema.AnyOf, openapi3.NewSchemaRef("", s))
+		}
+		return openapi3.NewSchemaRef("", schema)
+	}
+	for _, option := range options {
+		if option == "dive" {
+			break
+		}
+		rule, param, _ := strings.Cut(option, "=")
+		if !strings.HasPrefix(rule, "required_") {
+			continue
+		}
+		params := splitParams.FindAllString(param, -1)
+		switch rule {
+		case "required_if", "required_unless":
+			if len(params)%2 != 0 {
+				swagger.addError(fmt.Errorf("validate option %q: expects field and value pairs", option))
+				continue
+			}
+			condition := &openapi3.Schema{Properties: make(openapi3.Schemas)}
+			for i := 0; i < len(params); i += 2 {
+				field, property, ok := swagger.fieldProperty(type_, params[i], option)
+				if !ok {
+					continue
+				}
+				value, err := parseValue(router.JSONType(field.Type), strings.Trim(params[i+1], "'"))
+				if err != nil {
+					swagger.addError(fmt.Errorf("validate option %q: %w", option, err))
+					continue
+				}
+				condition.Required = append(condition.Required, property)
+				condition.Properties[property] = openapi3.NewSchemaRef("", &openapi3.Schema{Enum: []interface{}{value}})
+			}
+			if rule == "required_if" {
+				schemas = append(schemas, anyOf(&openapi3.Schema{Not: openapi3.NewSchemaRef("", condition)}, required))
+			} else {
+				schemas = append(schemas, anyOf(condition, required))
+			}
+		case "required_with", "required_without":
+			for _, param := range params {
+				if _, property, ok := swagger.fieldProperty(type_, param, option); ok {
+					present := &openapi3.Schema{Required: []string{property}}
+					if rule == "required_with" {
+						schemas = append(schemas, anyOf(&openapi3.Schema{Not: openapi3.NewSchemaRef("", present)}, required))
+					} else {
+						schemas = append(schemas, anyOf(present, required))
+					}
+				}
+			}
+		case "required_with_all":
+			present := &openapi3.Schema{}
+			for _, param := range params {
+				if _, property, ok := swagger.fieldProperty(type_, param, option); ok {
+					present.Required = append(present.Required, property)
+				}
+			}
+			schemas = append(schemas, anyOf(&openapi3.Schema{Not: openapi3.NewSchemaRef("", present)}, required))
+		case "required_without_all":
+			var present []*openapi3.Schema
+			for _, param := range params {
+				if _, property, ok := swagger.fieldProperty(type_, param, option); ok {
+					present = append(present, &openapi3.Schema{Required: []string{property}})
+				}
+			}
+			schemas = append(schemas, anyOf(append(present, required)...))
+		}
+	}
+	return schemas
+}
+
+// fieldProperty returns the field a rule refers to and its property name
+func (swagger *Swagger) fieldProperty(
+	type_ reflect.Type,
+	name string,
+	option string,
+) (reflect.StructField, string, bool) {
+	field, ok := type_.FieldByName(name)
+	if !ok {
+		swagger.addError(fmt.Errorf("validate option %q: %s has no field %s", option, type_, name))
+		return field, "", false
+	}
+	tags, err := structtag.Parse(string(field.Tag))
+	if err == nil {
+		if tag, err := tags.Get(constants.FORM); err == nil {
+			return field, tag.Name, true
+		}
+		if tag, err := tags.Get(constants.JSON); err == nil {
+			return field, tag.Name, true
+		}
+	}
+	return field, field.Name, true
+}
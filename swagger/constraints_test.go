@@ -0,0 +1,159 @@
+package swagger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyConstraints(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		validate string
+		want     string
+	}{
+		{"min of string", "", "min=2", `{"type":"string","minLength":2}`},
+		{"max of string", "", "max=5", `{"type":"string","maxLength":5}`},
+		{"len of string", "", "len=3", `{"type":"string","minLength":3,"maxLength":3}`},
+		{"min of slice", []string{}, "min=1", `{"type":"array","items":{"type":"string"},"minItems":1}`},
+		{"max of slice", []string{}, "max=3", `{"type":"array","items":{"type":"string"},"maxItems":3}`},
+		{"min of number", 0.0, "min=1.5", `{"type":"number","minimum":1.5}`},
+		{"max of integer", 0, "max=10", `{"type":"integer","maximum":10}`},
+		{"gte and lte of integer", 0, "gte=1,lte=9", `{"type":"integer","minimum":1,"maximum":9}`},
+		{"gt of integer", 0, "gt=0", `{"type":"integer","minimum":0,"exclusiveMinimum":true}`},
+		{"lt of number", 0.0, "lt=1", `{"type":"number","maximum":1,"exclusiveMaximum":true}`},
+		{"gt of string", "", "gt=2", `{"type":"string","minLength":3}`},
+		{"lt of slice", []int{}, "lt=4", `{"type":"array","items":{"type":"integer"},"maxItems":3}`},
+		{"lt 0 of string", "", "lt=0", `{"type":"string","maxLength":0}`},
+		{"negative bound of number", 0, "min=-1.5", `{"type":"integer","minimum":-1.5}`},
+		{"min of map", map[string]int{}, "min=1",
+			`{"type":"object","additionalProperties":{"type":"integer"},"minProperties":1}`},
+		{"unique", []string{}, "unique", `{"type":"array","items":{"type":"string"},"uniqueItems":true}`},
+		{"unique of string", "", "unique", `{"type":"string"}`},
+		{"dive", []string{}, "min=1,dive,email,max=20",
+			`{"type":"array","items":{"type":"string","format":"email","maxLength":20},"minItems":1}`},
+		{"dive into map", map[string]int{}, "dive,gt=0",
+			`{"type":"object","additionalProperties":{"type":"integer","minimum":0,"exclusiveMinimum":true}}`},
+		{"dive skipping keys", map[string]int{}, "dive,keys,min=2,endkeys,max=5",
+			`{"type":"object","additionalProperties":{"type":"integer","maximum":5}}`},
+		{"oneof of integer", 0, "oneof=1 2 3", `{"type":"integer","enum":[1,2,3]}`},
+		{"oneof of string", "", "oneof=a 'b c'", `{"type":"string","enum":["a","b c"]}`},
+		{"eq of boolean", false, "eq=true", `{"type":"boolean","enum":[true]}`},
+		{"format", "", "required,uuid4", `{"type":"string","format":"uuid"}`},
+		{"datetime", "", "datetime=2006-01-02", `{"type":"string","format":"date"}`},
+		{"patterns", "", "startswith=a.,alpha",
+			`{"type":"string","pattern":"^a\\.","allOf":[{"pattern":"^[a-zA-Z]+$"}]}`},
+		{"alternatives", "", "email|url", `{"type":"string"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			swagger := built(t)
+			schema := swagger.getValidateSchemaByOptions(test.value, strings.Split(test.validate, ","))
+			if len(swagger.errors) > 0 {
+				t.Fatal(swagger.errors)
+			}
+			assertJSON(t, schema.Value, test.want)
+		})
+	}
+}
+
+func TestApplyConstraintsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		validate string
+	}{
+		{"oneof of integer", 0, "oneof=1 a"},
+		{"bound", "", "min=a"},
+		{"negative length", "", "min=-1"},
+		{"fractional length", []string{}, "max=1.5"},
+		{"negative exclusive length", "", "lt=-2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			swagger := built(t)
+			swagger.getValidateSchemaByOptions(test.value, strings.Split(test.validate, ","))
+			if len(swagger.errors) == 0 {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+type conditionalModel struct {
+	Kind    string `json:"kind"`
+	Count   int    `json:"count"`
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+	Company string `json:"company" validate:"required_if=Kind business Count 2"`
+	Contact string `json:"contact" validate:"required_without=Email"`
+	Name    string `json:"name" validate:"required_with=Email Phone"`
+	Owner   string `json:"owner" validate:"required_unless=Kind private"`
+}
+
+func TestConditionalRequired(t *testing.T) {
+	type_ := reflect.TypeOf(conditionalModel{})
+	tests := []struct {
+		property string
+		validate string
+		want     string
+	}{
+		{"company", "required_if=Kind business Count 2",
+			`[{"anyOf":[{"not":{"required":["kind","count"],"properties":{"count":{"enum":[2]},"kind":{"enum":["business"]}}}},{"required":["company"]}]}]`},
+		{"contact", "required_without=Email",
+			`[{"anyOf":[{"required":["email"]},{"required":["contact"]}]}]`},
+		{"name", "required_with=Email Phone",
+			`[{"anyOf":[{"not":{"required":["email"]}},{"required":["name"]}]},{"anyOf":[{"not":{"required":["phone"]}},{"required":["name"]}]}]`},
+		{"owner", "required_unless=Kind private",
+			`[{"anyOf":[{"required":["kind"],"properties":{"kind":{"enum":["private"]}}},{"required":["owner"]}]}]`},
+	}
+	for _, test := range tests {
+		t.Run(test.validate, func(t *testing.T) {
+			swagger := built(t)
+			schemas := swagger.getConditionalRequired(type_, test.property, strings.Split(test.validate, ","))
+			if len(swagger.errors) > 0 {
+				t.Fatal(swagger.errors)
+			}
+			assertJSON(t, schemas, test.want)
+		})
+	}
+
+	// the rules are added to the schema of the model
+	schema := built(t).getRequestSchemaByModel(conditionalModel{})
+	if len(schema.AllOf) != 5 {
+		t.Errorf("got %d conditions, want 5", len(schema.AllOf))
+	}
+}
+
+func TestConditionalRequiredErrors(t *testing.T) {
+	for _, validate := range []string{"required_if=Kind", "required_with=Missing", "required_if=Count a"} {
+		t.Run(validate, func(t *testing.T) {
+			swagger := built(t)
+			swagger.getConditionalRequired(reflect.TypeOf(conditionalModel{}), "name", []string{validate})
+			if len(swagger.errors) == 0 {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+// assertJSON compares value written as JSON with want, ignoring the order of object keys
+func assertJSON(t *testing.T, value interface{}, want string) {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, expected interface{}
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal([]byte(want), &expected); err != nil {
+		t.Fatalf("want: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %s\nwant %s", data, want)
+	}
+}
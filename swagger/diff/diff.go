@@ -0,0 +1,187 @@
+// Package diff compares two OpenAPI documents, such as the ones exported from the previous
+// and the next release of an API, and classifies each change as breaking or not for clients
+// written against the older one.
+package diff
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Change is a difference between two documents
+type Change struct {
+	Breaking bool `json:"breaking"`
+	// Operation is the method and path of the operation changed, like GET /users/{id}
+	Operation string `json:"operation"`
+	// Location is the part of the operation changed, like query parameter name or response 200
+	Location string `json:"location,omitempty"`
+	// Field is the path of the property changed in the schema of the location, like user.name
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Load reads a document written as JSON or YAML by this library, 3.1 documents are converted
+// to 3.0 first as the comparison is done on 3.0 documents
+func Load(path string) (*openapi3.T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isOpenAPI31(data) {
+		if data, err = convertOpenAPI30(data); err != nil {
+			return nil, fmt.Errorf("converting %s to OpenAPI 3.0: %w", path, err)
+		}
+	}
+	return openapi3.NewLoader().LoadFromData(data)
+}
+
+// Compare returns the changes from the base document to the revision
+func Compare(base *openapi3.T, revision *openapi3.T) *Report {
+	d := &differ{}
+	for _, path := range sortedKeys(base.Paths) {
+		for _, method := range sortedKeys(base.Paths[path].Operations()) {
+			operation := method + " " + path
+			old := base.Paths[path].GetOperation(method)
+			var new *openapi3.Operation
+			if pathItem := revision.Paths.Find(path); pathItem != nil {
+				new = pathItem.GetOperation(method)
+			}
+			if new == nil {
+				d.add(true, operation, "", "", "operation removed")
+				continue
+			}
+			d.operation(operation, old, new)
+			d.security(operation, effectiveSecurity(base, old), effectiveSecurity(revision, new))
+		}
+	}
+	for _, path := range sortedKeys(revision.Paths) {
+		for _, method := range sortedKeys(revision.Paths[path].Operations()) {
+			if pathItem := base.Paths.Find(path); pathItem == nil || pathItem.GetOperation(method) == nil {
+				d.add(false, method+" "+path, "", "", "operation added")
+			}
+		}
+	}
+	return &Report{Changes: d.changes}
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(breaking bool, operation string, location string, field string, message string) {
+	d.changes = append(d.changes, Change{
+		Breaking:  breaking,
+		Operation: operation,
+		Location:  location,
+		Field:     field,
+		Message:   message,
+	})
+}
+
+func (d *differ) operation(operation string, old *openapi3.Operation, new *openapi3.Operation) {
+	d.parameters(operation, old.Parameters, new.Parameters)
+	d.requestBody(operation, old.RequestBody, new.RequestBody)
+	d.responses(operation, old.Responses, new.Responses)
+}
+
+func (d *differ) parameters(operation string, old openapi3.Parameters, new openapi3.Parameters) {
+	for _, newRef := range new {
+		parameter := newRef.Value
+		location := parameter.In + " parameter " + parameter.Name
+		oldParameter := old.GetByInAndName(parameter.In, parameter.Name)
+		if oldParameter == nil {
+			if parameter.Required {
+				d.add(true, operation, location, "", "required parameter added")
+			} else {
+				d.add(false, operation, location, "", "optional parameter added")
+			}
+			continue
+		}
+		if parameter.Required && !oldParameter.Required {
+			d.add(true, operation, location, "", "parameter became required")
+		} else if !parameter.Required && oldParameter.Required {
+			d.add(false, operation, location, "", "parameter became optional")
+		}
+		if oldParameter.Schema != nil && parameter.Schema != nil {
+			s := &schemaDiffer{differ: d, operation: operation, location: location, request: true}
+			s.compare("", oldParameter.Schema.Value, parameter.Schema.Value)
+		}
+	}
+	for _, oldRef := range old {
+		parameter := oldRef.Value
+		if new.GetByInAndName(parameter.In, parameter.Name) == nil {
+			d.add(false, operation, parameter.In+" parameter "+parameter.Name, "", "parameter removed")
+		}
+	}
+}
+
+func (d *differ) requestBody(operation string, old *openapi3.RequestBodyRef, new *openapi3.RequestBodyRef) {
+	const location = "request body"
+	switch {
+	case new == nil || new.Value == nil:
+		if old != nil && old.Value != nil {
+			d.add(false, operation, location, "", "request body removed")
+		}
+		return
+	case old == nil || old.Value == nil:
+		d.add(new.Value.Required, operation, location, "", "request body added")
+		return
+	}
+	if new.Value.Required && !old.Value.Required {
+		d.add(true, operation, location, "", "request body became required")
+	}
+	d.content(operation, location, old.Value.Content, new.Value.Content, true)
+}
+
+func (d *differ) responses(operation string, old openapi3.Responses, new openapi3.Responses) {
+	for _, code := range sortedKeys(old) {
+		location := "response " + code
+		newResponse := new[code]
+		if newResponse == nil || newResponse.Value == nil {
+			// clients rely on the responses of successful requests
+			d.add(strings.HasPrefix(code, "2"), operation, location, "", "response removed")
+			continue
+		}
+		if old[code].Value != nil {
+			d.content(operation, location, old[code].Value.Content, newResponse.Value.Content, false)
+		}
+	}
+	for _, code := range sortedKeys(new) {
+		if _, ok := old[code]; !ok {
+			d.add(false, operation, "response "+code, "", "response added")
+		}
+	}
+}
+
+// content compares the schemas of the media types of a request body or a response
+func (d *differ) content(operation string, location string, old openapi3.Content, new openapi3.Content, request bool) {
+	for _, mediaType := range sortedKeys(old) {
+		newMediaType, ok := new[mediaType]
+		if !ok {
+			d.add(true, operation, location, "", fmt.Sprintf("media type %s removed", mediaType))
+			continue
+		}
+		if old[mediaType].Schema != nil && newMediaType.Schema != nil {
+			s := &schemaDiffer{differ: d, operation: operation, location: location, request: request}
+			s.compare("", old[mediaType].Schema.Value, newMediaType.Schema.Value)
+		}
+	}
+	for _, mediaType := range sortedKeys(new) {
+		if _, ok := old[mediaType]; !ok {
+			d.add(false, operation, location, "", fmt.Sprintf("media type %s added", mediaType))
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
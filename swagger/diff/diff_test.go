@@ -0,0 +1,230 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+	"github.com/long2ice/fibers/swagger"
+)
+
+// document reads a document written as JSON
+func document(t *testing.T, data string) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(data))
+	if err != nil {
+		t.Fatalf("%s: %v", data, err)
+	}
+	return doc
+}
+
+func TestCompare(t *testing.T) {
+	const base = `{"openapi":"3.0.3","info":{"title":"","version":""},"paths":{"/users":{"get":{` +
+		`"parameters":[{"in":"query","name":"page","schema":{"type":"integer"}}],` +
+		`"responses":{"200":{"description":""},"404":{"description":""}}}}}}`
+	tests := []struct {
+		name     string
+		revision string
+		change   Change
+	}{
+		{"operation removed", `{"openapi":"3.0.3","info":{"title":"","version":""},"paths":{}}`,
+			Change{Breaking: true, Operation: "GET /users", Message: "operation removed"}},
+		{"required parameter added", `{"openapi":"3.0.3","info":{"title":"","version":""},"paths":{"/users":{"get":{` +
+			`"parameters":[{"in":"query","name":"page","schema":{"type":"integer"}},` +
+			`{"in":"query","name":"size","required":true,"schema":{"type":"integer"}}],` +
+			`"responses":{"200":{"description":""},"404":{"description":""}}}}}}`,
+			Change{Breaking: true, Operation: "GET /users", Location: "query parameter size", Message: "required parameter added"}},
+		{"parameter became required", `{"openapi":"3.0.3","info":{"title":"","version":""},"paths":{"/users":{"get":{` +
+			`"parameters":[{"in":"query","name":"page","required":true,"schema":{"type":"integer"}}],` +
+			`"responses":{"200":{"description":""},"404":{"description":""}}}}}}`,
+			Change{Breaking: true, Operation: "GET /users", Location: "query parameter page", Message: "parameter became required"}},
+		{"parameter type changed", `{"openapi":"3.0.3","info":{"title":"","version":""},"paths":{"/users":{"get":{` +
+			`"parameters":[{"in":"query","name":"page","schema":{"type":"string"}}],` +
+			`"responses":{"200":{"description":""},"404":{"description":""}}}}}}`,
+			Change{Breaking: true, Operation: "GET /users", Location: "query parameter page",
+				Message: "type changed from integer to string"}},
+		{"error response removed", `{"openapi":"3.0.3","info":{"title":"","version":""},"paths":{"/users":{"get":{` +
+			`"parameters":[{"in":"query","name":"page","schema":{"type":"integer"}}],` +
+			`"responses":{"200":{"description":""}}}}}}`,
+			Change{Operation: "GET /users", Location: "response 404", Message: "response removed"}},
+		{"successful response removed", `{"openapi":"3.0.3","info":{"title":"","version":""},"paths":{"/users":{"get":{` +
+			`"parameters":[{"in":"query","name":"page","schema":{"type":"integer"}}],` +
+			`"responses":{"404":{"description":""}}}}}}`,
+			Change{Breaking: true, Operation: "GET /users", Location: "response 200", Message: "response removed"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := Compare(document(t, base), document(t, test.revision))
+			if len(report.Changes) != 1 || report.Changes[0] != test.change {
+				t.Errorf("got %+v, want %+v", report.Changes, test.change)
+			}
+		})
+	}
+}
+
+type address struct {
+	City string `json:"city"`
+}
+
+type userReq struct {
+	Nickname *string  `json:"nickname"`
+	Address  *address `json:"address"`
+	Age      int      `json:"age" validate:"gt=0,lt=150" example:"30"`
+	Role     *string  `json:"role" validate:"omitempty,oneof=admin user"`
+	Data     []byte   `json:"data"`
+}
+
+// export writes the document of an app as version to a file and returns its path
+func export(t *testing.T, version string) string {
+	t.Helper()
+	s := swagger.New("test", "test", "1.0.0", swagger.OpenAPIVersion(version))
+	r := router.New(func(c *fiber.Ctx, req userReq) error { return nil })
+	r.Path, r.Method = "/users", fiber.MethodPost
+	s.Routers = map[string]map[string]*router.Router{"/users": {fiber.MethodPost: r}}
+	if err := s.BuildOpenAPI(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "openapi.json")
+	if err = os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadOpenAPI31(t *testing.T) {
+	base, err := Load(export(t, swagger.OpenAPI30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	revision, err := Load(export(t, swagger.OpenAPI31))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the 3.1 document of the same app reads back as its 3.0 one
+	if report := Compare(base, revision); len(report.Changes) > 0 {
+		t.Errorf("got %+v, want no change", report.Changes)
+	}
+	schema := revision.Components.Schemas["userReq"].Value
+	if nickname := schema.Properties["nickname"].Value; nickname.Type != "string" || !nickname.Nullable {
+		t.Errorf("nickname is %s nullable %v, want a nullable string", nickname.Type, nickname.Nullable)
+	}
+	if age := schema.Properties["age"].Value; age.Min == nil || *age.Min != 0 || !age.ExclusiveMin {
+		t.Errorf("age has minimum %v exclusive %v, want exclusive 0", age.Min, age.ExclusiveMin)
+	}
+}
+
+func TestLoadOpenAPI31YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	data := `openapi: 3.1.0
+info:
+  title: test
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        200:
+          description: user
+          content:
+            application/json:
+              schema:
+                type: [string, "null"]
+                enum: [a, null]
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := doc.Paths["/users"].Get.Responses.Get(200).Value.Content.Get("application/json").Schema.Value
+	if schema.Type != "string" || !schema.Nullable || len(schema.Enum) != 1 {
+		t.Errorf("got %s nullable %v enum %v, want a nullable string of a", schema.Type, schema.Nullable, schema.Enum)
+	}
+}
+
+func TestCompareSecurity(t *testing.T) {
+	// doc returns a document whose security is docSecurity and whose operation declares
+	// operationSecurity unless it is empty
+	doc := func(docSecurity string, operationSecurity string) *openapi3.T {
+		operation := `"responses":{"200":{"description":""}}`
+		if operationSecurity != "" {
+			operation += `,"security":` + operationSecurity
+		}
+		data := `{"openapi":"3.0.3","info":{"title":"","version":""},"paths":{"/users":{"get":{` + operation + `}}}`
+		if docSecurity != "" {
+			data += `,"security":` + docSecurity
+		}
+		return document(t, data+`}`)
+	}
+	change := func(breaking bool, message string) Change {
+		return Change{Breaking: breaking, Operation: "GET /users", Location: "security", Message: message}
+	}
+	tests := []struct {
+		name     string
+		base     *openapi3.T
+		revision *openapi3.T
+		changes  []Change
+	}{
+		{"unchanged", doc("", `[{"key":[]}]`), doc(`[{"key":[]}]`, ""), nil},
+		{"security added", doc("", ""), doc("", `[{"key":[]}]`), []Change{
+			change(true, "security became required"),
+			change(false, "security requirement key added"),
+		}},
+		{"security added to the document", doc("", ""), doc(`[{"key":[]}]`, ""), []Change{
+			change(true, "security became required"),
+			change(false, "security requirement key added"),
+		}},
+		{"security removed by the operation", doc(`[{"key":[]}]`, ""), doc(`[{"key":[]}]`, `[]`), []Change{
+			change(false, "security requirement key removed"),
+			change(false, "security became optional"),
+		}},
+		{"security made optional", doc("", `[{"key":[]}]`), doc("", `[{"key":[]},{}]`), []Change{
+			change(false, "security became optional"),
+		}},
+		{"scheme added to a requirement", doc("", `[{"key":[]}]`), doc("", `[{"key":[],"oauth":[]}]`), []Change{
+			change(true, "security requirement key removed"),
+			change(false, "security requirement key and oauth added"),
+		}},
+		{"scheme removed from a requirement", doc("", `[{"key":[],"oauth":[]}]`), doc("", `[{"key":[]}]`), []Change{
+			change(false, "security requirement key and oauth removed"),
+			change(false, "security requirement key added"),
+		}},
+		{"alternative added", doc("", `[{"key":[]}]`), doc("", `[{"key":[]},{"oauth":[]}]`), []Change{
+			change(false, "security requirement oauth added"),
+		}},
+		{"alternative removed", doc("", `[{"key":[]},{"oauth":[]}]`), doc("", `[{"key":[]}]`), []Change{
+			change(true, "security requirement oauth removed"),
+		}},
+		{"scheme replaced", doc("", `[{"key":[]}]`), doc("", `[{"oauth":[]}]`), []Change{
+			change(true, "security requirement key removed"),
+			change(false, "security requirement oauth added"),
+		}},
+		{"scope added", doc("", `[{"oauth":["read"]}]`), doc("", `[{"oauth":["write","read"]}]`), []Change{
+			change(true, "security requirement oauth (read) removed"),
+			change(false, "security requirement oauth (read, write) added"),
+		}},
+		{"scope removed", doc("", `[{"oauth":["read","write"]}]`), doc("", `[{"oauth":["read"]}]`), []Change{
+			change(false, "security requirement oauth (read, write) removed"),
+			change(false, "security requirement oauth (read) added"),
+		}},
+		{"scopes reordered", doc("", `[{"oauth":["read","write"]}]`), doc("", `[{"oauth":["write","read"]}]`), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := Compare(test.base, test.revision)
+			if !reflect.DeepEqual(report.Changes, test.changes) {
+				t.Errorf("got %+v, want %+v", report.Changes, test.changes)
+			}
+		})
+	}
+}
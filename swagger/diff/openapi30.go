@@ -0,0 +1,159 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/long2ice/fibers/swagger/internal/walk"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPI30 is the version of the documents converted from 3.1
+const openAPI30 = "3.0.3"
+
+// isOpenAPI31 reports whether data is a 3.1 document, which kin-openapi can't read as the
+// type of its schemas may be an array
+func isOpenAPI31(data []byte) bool {
+	var doc struct {
+		OpenAPI string `yaml:"openapi"`
+	}
+	// JSON is YAML, errors are left to the loader
+	_ = yaml.Unmarshal(data, &doc)
+	return strings.HasPrefix(doc.OpenAPI, "3.1")
+}
+
+// convertOpenAPI30 converts a 3.1 document written as JSON or YAML to a 3.0 one written as
+// JSON, reversing the conversion of the documents written as 3.1 by this library. Keywords of
+// 3.1 that 3.0 can't express, such as types listing several non-null types, are dropped.
+func convertOpenAPI30(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	doc, ok := stringKeys(value).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("document is not an object")
+	}
+	doc["openapi"] = openAPI30
+	walk.Schemas(doc, convertSchema)
+	return json.Marshal(doc)
+}
+
+// stringKeys turns the objects of YAML with keys that aren't strings, such as unquoted
+// status codes, into objects with string keys
+func stringKeys(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = stringKeys(item)
+		}
+		return value
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			object[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return object
+	case []interface{}:
+		for i, item := range value {
+			value[i] = stringKeys(item)
+		}
+		return value
+	}
+	return value
+}
+
+// convertSchema rewrites the 3.1 keywords of a schema and its subschemas as their 3.0 ones
+func convertSchema(schema map[string]interface{}) {
+	walk.Subschemas(schema, convertSchema)
+	for key, exclusive := range map[string]string{"minimum": "exclusiveMinimum", "maximum": "exclusiveMaximum"} {
+		if bound, ok := schema[exclusive]; ok {
+			if _, ok = bound.(bool); !ok {
+				schema[key] = bound
+				schema[exclusive] = true
+			}
+		}
+	}
+	if examples, ok := schema["examples"].([]interface{}); ok {
+		if len(examples) > 0 {
+			schema["example"] = examples[0]
+		}
+		delete(schema, "examples")
+	}
+	if value, ok := schema["const"]; ok {
+		schema["enum"] = []interface{}{value}
+		delete(schema, "const")
+	}
+	if _, ok := schema["contentMediaType"]; ok {
+		schema["format"] = "binary"
+		delete(schema, "contentMediaType")
+	}
+	if schema["contentEncoding"] == "base64" {
+		schema["format"] = "byte"
+	}
+	delete(schema, "contentEncoding")
+	withoutNull(schema)
+}
+
+// withoutNull turns the null type of a schema, in its type or as a variant of anyOf, into
+// nullable
+func withoutNull(schema map[string]interface{}) {
+	if types, ok := schema["type"].([]interface{}); ok {
+		var nonNull []interface{}
+		for _, type_ := range types {
+			if type_ == "null" {
+				schema["nullable"] = true
+			} else {
+				nonNull = append(nonNull, type_)
+			}
+		}
+		if len(nonNull) == 1 {
+			schema["type"] = nonNull[0]
+		} else {
+			delete(schema, "type")
+		}
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			values := enum[:0]
+			for _, value := range enum {
+				if value != nil {
+					values = append(values, value)
+				}
+			}
+			schema["enum"] = values
+		}
+	}
+	anyOf, ok := schema["anyOf"].([]interface{})
+	if !ok {
+		return
+	}
+	var variants []interface{}
+	for _, variant := range anyOf {
+		if variant, ok := variant.(map[string]interface{}); ok && len(variant) == 1 && variant["type"] == "null" {
+			schema["nullable"] = true
+			continue
+		}
+		variants = append(variants, variant)
+	}
+	if len(variants) == len(anyOf) {
+		return
+	}
+	delete(schema, "anyOf")
+	if len(variants) != 1 {
+		if len(variants) > 1 {
+			schema["anyOf"] = variants
+		}
+		return
+	}
+	variant, ok := variants[0].(map[string]interface{})
+	if _, ref := variant["$ref"]; !ok || ref {
+		// references can't have nullable next to them in 3.0
+		schema["allOf"] = variants
+		return
+	}
+	for key, value := range variant {
+		if _, ok := schema[key]; !ok {
+			schema[key] = value
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report is the list of changes between two documents, in the order of their operations
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// Breaking returns the changes that break clients of the base document
+func (r *Report) Breaking() []Change {
+	var changes []Change
+	for _, change := range r.Changes {
+		if change.Breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// WriteText writes a line per change, like
+//
+//	breaking      GET /users/{id}  response 200  name: property removed
+func (r *Report) WriteText(w io.Writer) error {
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+	for _, change := range r.Changes {
+		kind := "non-breaking"
+		if change.Breaking {
+			kind = "breaking"
+		}
+		parts := []string{change.Operation}
+		if change.Location != "" {
+			parts = append(parts, change.Location)
+		}
+		message := change.Message
+		if change.Field != "" {
+			message = change.Field + ": " + message
+		}
+		if _, err := fmt.Fprintf(w, "%-13s %s  %s\n", kind, strings.Join(parts, "  "), message); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d changes, %d breaking\n", len(r.Changes), len(r.Breaking()))
+	return err
+}
+
+// WriteJSON writes the report as a JSON object with its changes and whether any breaks clients
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	changes := r.Changes
+	if changes == nil {
+		changes = []Change{}
+	}
+	return encoder.Encode(struct {
+		Breaking bool     `json:"breaking"`
+		Changes  []Change `json:"changes"`
+	}{len(r.Breaking()) > 0, changes})
+}
@@ -0,0 +1,241 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaDiffer compares the schemas of a request, where the client writes the values and
+// narrowing what is accepted breaks it, or of a response, where the client reads them and
+// widening what is returned breaks it
+type schemaDiffer struct {
+	*differ
+	operation string
+	location  string
+	request   bool
+	visiting  map[[2]*openapi3.Schema]bool
+}
+
+func (s *schemaDiffer) add(breaking bool, field string, message string) {
+	s.differ.add(breaking, s.operation, s.location, field, message)
+}
+
+func (s *schemaDiffer) compare(field string, old *openapi3.Schema, new *openapi3.Schema) {
+	if old == nil || new == nil {
+		return
+	}
+	// recursive schemas stop at the first schema already being compared, keyed by the
+	// schemas of the documents as unwrap copies them
+	key := [2]*openapi3.Schema{old, new}
+	if s.visiting == nil {
+		s.visiting = make(map[[2]*openapi3.Schema]bool)
+	}
+	if s.visiting[key] {
+		return
+	}
+	s.visiting[key] = true
+	defer delete(s.visiting, key)
+	old, new = unwrap(old), unwrap(new)
+
+	if old.Type != new.Type {
+		s.add(true, field, fmt.Sprintf("type changed from %s to %s", typeName(old.Type), typeName(new.Type)))
+		return
+	}
+	if old.Format != new.Format {
+		s.add(true, field, fmt.Sprintf("format changed from %q to %q", old.Format, new.Format))
+	}
+	if old.Nullable != new.Nullable {
+		// requests that send null break if it is no longer accepted, and responses if it is returned
+		breaking := s.request == old.Nullable
+		if new.Nullable {
+			s.add(breaking, field, "became nullable")
+		} else {
+			s.add(breaking, field, "is no longer nullable")
+		}
+	}
+	if old.Pattern != new.Pattern {
+		s.add(s.request && new.Pattern != "", field, fmt.Sprintf("pattern changed from %q to %q", old.Pattern, new.Pattern))
+	}
+	s.enum(field, old.Enum, new.Enum)
+	s.bounds(field, old, new)
+	if old.Items != nil && new.Items != nil {
+		s.compare(field+"[]", old.Items.Value, new.Items.Value)
+	}
+	if old.AdditionalProperties.Schema != nil && new.AdditionalProperties.Schema != nil {
+		s.compare(field+"{}", old.AdditionalProperties.Schema.Value, new.AdditionalProperties.Schema.Value)
+	}
+	s.properties(field, old, new)
+	s.variants(field, old.OneOf, new.OneOf)
+	s.variants(field, old.AnyOf, new.AnyOf)
+}
+
+func (s *schemaDiffer) properties(field string, old *openapi3.Schema, new *openapi3.Schema) {
+	oldRequired, newRequired := set(old.Required), set(new.Required)
+	for _, name := range sortedKeys(old.Properties) {
+		property := join(field, name)
+		newProperty, ok := new.Properties[name]
+		if !ok {
+			// clients reading the property break, while ones still sending it are ignored
+			s.add(!s.request, property, "property removed")
+			continue
+		}
+		switch {
+		case newRequired[name] && !oldRequired[name]:
+			s.add(s.request, property, "property became required")
+		case !newRequired[name] && oldRequired[name]:
+			s.add(!s.request, property, "property became optional")
+		}
+		s.compare(property, old.Properties[name].Value, newProperty.Value)
+	}
+	for _, name := range sortedKeys(new.Properties) {
+		if _, ok := old.Properties[name]; !ok {
+			if newRequired[name] {
+				s.add(s.request, join(field, name), "required property added")
+			} else {
+				s.add(false, join(field, name), "property added")
+			}
+		}
+	}
+}
+
+// enum reports values removed from requests and added to responses as breaking
+func (s *schemaDiffer) enum(field string, old []interface{}, new []interface{}) {
+	if len(old) == 0 && len(new) == 0 {
+		return
+	}
+	if len(new) > 0 && len(old) == 0 {
+		s.add(s.request, field, fmt.Sprintf("values limited to %v", new))
+		return
+	}
+	if len(new) == 0 {
+		s.add(!s.request, field, "values no longer limited")
+		return
+	}
+	for _, value := range old {
+		if !contains(new, value) {
+			s.add(s.request, field, fmt.Sprintf("enum value %v removed", value))
+		}
+	}
+	for _, value := range new {
+		if !contains(old, value) {
+			s.add(!s.request, field, fmt.Sprintf("enum value %v added", value))
+		}
+	}
+}
+
+// bounds reports narrowed bounds of requests as breaking
+func (s *schemaDiffer) bounds(field string, old *openapi3.Schema, new *openapi3.Schema) {
+	if !s.request {
+		return
+	}
+	if raised(old.Min, new.Min) {
+		s.add(true, field, fmt.Sprintf("minimum raised to %v", *new.Min))
+	}
+	if lowered(old.Max, new.Max) {
+		s.add(true, field, fmt.Sprintf("maximum lowered to %v", *new.Max))
+	}
+	if new.MinLength > old.MinLength {
+		s.add(true, field, fmt.Sprintf("minLength raised to %d", new.MinLength))
+	}
+	if lowered(toFloat(old.MaxLength), toFloat(new.MaxLength)) {
+		s.add(true, field, fmt.Sprintf("maxLength lowered to %d", *new.MaxLength))
+	}
+	if new.MinItems > old.MinItems {
+		s.add(true, field, fmt.Sprintf("minItems raised to %d", new.MinItems))
+	}
+	if lowered(toFloat(old.MaxItems), toFloat(new.MaxItems)) {
+		s.add(true, field, fmt.Sprintf("maxItems lowered to %d", *new.MaxItems))
+	}
+}
+
+// variants compares the variants of oneOf and anyOf by the components they reference,
+// removed variants break requests and added ones break responses
+func (s *schemaDiffer) variants(field string, old openapi3.SchemaRefs, new openapi3.SchemaRefs) {
+	oldRefs, newRefs := refs(old), refs(new)
+	for _, ref := range sortedKeys(oldRefs) {
+		if newRef, ok := newRefs[ref]; ok {
+			s.compare(field, oldRefs[ref].Value, newRef.Value)
+		} else {
+			s.add(s.request, field, fmt.Sprintf("variant %s removed", ref))
+		}
+	}
+	for _, ref := range sortedKeys(newRefs) {
+		if _, ok := oldRefs[ref]; !ok {
+			s.add(!s.request, field, fmt.Sprintf("variant %s added", ref))
+		}
+	}
+}
+
+// unwrap returns the schema referenced by a schema wrapping a reference in allOf to set
+// nullable or annotations next to it, keeping nullable
+func unwrap(schema *openapi3.Schema) *openapi3.Schema {
+	if schema == nil || len(schema.AllOf) != 1 || schema.Type != "" || len(schema.Properties) > 0 || schema.AllOf[0].Value == nil {
+		return schema
+	}
+	inner := *unwrap(schema.AllOf[0].Value)
+	inner.Nullable = inner.Nullable || schema.Nullable
+	return &inner
+}
+
+func refs(schemas openapi3.SchemaRefs) map[string]*openapi3.SchemaRef {
+	refs := make(map[string]*openapi3.SchemaRef, len(schemas))
+	for i, schema := range schemas {
+		name := schema.Ref
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+		refs[name] = schema
+	}
+	return refs
+}
+
+func typeName(type_ string) string {
+	if type_ == "" {
+		return "any"
+	}
+	return type_
+}
+
+func join(field string, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+func set(values []string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, value := range values {
+		m[value] = true
+	}
+	return m
+}
+
+func contains(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(value *uint64) *float64 {
+	if value == nil {
+		return nil
+	}
+	f := float64(*value)
+	return &f
+}
+
+// raised reports whether a lower bound has been added or raised
+func raised(old *float64, new *float64) bool {
+	return new != nil && (old == nil || *new > *old)
+}
+
+// lowered reports whether an upper bound has been added or lowered
+func lowered(old *float64, new *float64) bool {
+	return new != nil && (old == nil || *new < *old)
+}
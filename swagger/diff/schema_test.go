@@ -0,0 +1,125 @@
+package diff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schema reads a schema written as JSON
+func schema(t *testing.T, data string) *openapi3.Schema {
+	t.Helper()
+	schema := openapi3.NewSchema()
+	if err := json.Unmarshal([]byte(data), schema); err != nil {
+		t.Fatalf("%s: %v", data, err)
+	}
+	return schema
+}
+
+// outcome is how a change of a schema is reported
+type outcome int
+
+const (
+	ignored outcome = iota
+	compatible
+	breaking
+)
+
+func TestCompareSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		message string
+		// how the change is reported in requests and in responses
+		request  outcome
+		response outcome
+	}{
+		{"type changed", `{"type":"string"}`, `{"type":"integer"}`,
+			"type changed from string to integer", breaking, breaking},
+		{"format changed", `{"type":"string","format":"date"}`, `{"type":"string","format":"date-time"}`,
+			`format changed from "date" to "date-time"`, breaking, breaking},
+		{"enum value removed", `{"type":"string","enum":["a","b"]}`, `{"type":"string","enum":["a"]}`,
+			"enum value b removed", breaking, compatible},
+		{"enum value added", `{"type":"string","enum":["a"]}`, `{"type":"string","enum":["a","b"]}`,
+			"enum value b added", compatible, breaking},
+		{"values limited", `{"type":"string"}`, `{"type":"string","enum":["a"]}`,
+			"values limited to [a]", breaking, compatible},
+		{"values no longer limited", `{"type":"string","enum":["a"]}`, `{"type":"string"}`,
+			"values no longer limited", compatible, breaking},
+		{"became nullable", `{"type":"string"}`, `{"type":"string","nullable":true}`,
+			"became nullable", compatible, breaking},
+		{"no longer nullable", `{"type":"string","nullable":true}`, `{"type":"string"}`,
+			"is no longer nullable", breaking, compatible},
+		{"nullable reference", `{"allOf":[{"type":"object"}]}`, `{"allOf":[{"type":"object"}],"nullable":true}`,
+			"became nullable", compatible, breaking},
+		{"pattern added", `{"type":"string"}`, `{"type":"string","pattern":"^a"}`,
+			`pattern changed from "" to "^a"`, breaking, compatible},
+		{"minimum raised", `{"type":"integer","minimum":1}`, `{"type":"integer","minimum":2}`,
+			"minimum raised to 2", breaking, ignored},
+		{"maxLength lowered", `{"type":"string"}`, `{"type":"string","maxLength":5}`,
+			"maxLength lowered to 5", breaking, ignored},
+		{"minItems raised", `{"type":"array","items":{"type":"string"}}`,
+			`{"type":"array","items":{"type":"string"},"minItems":1}`, "minItems raised to 1", breaking, ignored},
+		{"property removed", `{"type":"object","properties":{"a":{"type":"string"}}}`, `{"type":"object"}`,
+			"property removed", compatible, breaking},
+		{"property added", `{"type":"object"}`, `{"type":"object","properties":{"a":{"type":"string"}}}`,
+			"property added", compatible, compatible},
+		{"required property added", `{"type":"object"}`,
+			`{"type":"object","properties":{"a":{"type":"string"}},"required":["a"]}`,
+			"required property added", breaking, compatible},
+		{"property became required", `{"type":"object","properties":{"a":{"type":"string"}}}`,
+			`{"type":"object","properties":{"a":{"type":"string"}},"required":["a"]}`,
+			"property became required", breaking, compatible},
+		{"property became optional", `{"type":"object","properties":{"a":{"type":"string"}},"required":["a"]}`,
+			`{"type":"object","properties":{"a":{"type":"string"}}}`,
+			"property became optional", compatible, breaking},
+		{"item type changed", `{"type":"array","items":{"type":"string"}}`, `{"type":"array","items":{"type":"integer"}}`,
+			"type changed from string to integer", breaking, breaking},
+		{"variant removed", `{"oneOf":[{"$ref":"#/a"},{"$ref":"#/b"}]}`, `{"oneOf":[{"$ref":"#/a"}]}`,
+			"variant #/b removed", breaking, compatible},
+		{"variant added", `{"oneOf":[{"$ref":"#/a"}]}`, `{"oneOf":[{"$ref":"#/a"},{"$ref":"#/b"}]}`,
+			"variant #/b added", compatible, breaking},
+	}
+	for _, test := range tests {
+		for _, direction := range []struct {
+			name    string
+			request bool
+			outcome outcome
+		}{{"request", true, test.request}, {"response", false, test.response}} {
+			t.Run(test.name+" in "+direction.name, func(t *testing.T) {
+				d := &differ{}
+				s := &schemaDiffer{differ: d, operation: "POST /", location: direction.name, request: direction.request}
+				s.compare("", schema(t, test.old), schema(t, test.new))
+				if direction.outcome == ignored {
+					if len(d.changes) != 0 {
+						t.Errorf("got %+v, want no change", d.changes)
+					}
+					return
+				}
+				if len(d.changes) != 1 {
+					t.Fatalf("got %+v, want one change", d.changes)
+				}
+				change := d.changes[0]
+				if change.Message != test.message || change.Breaking != (direction.outcome == breaking) {
+					t.Errorf("got %q breaking %v, want %q breaking %v",
+						change.Message, change.Breaking, test.message, direction.outcome == breaking)
+				}
+			})
+		}
+	}
+}
+
+func TestCompareRecursiveSchema(t *testing.T) {
+	old := schema(t, `{"type":"object","properties":{"name":{"type":"string"}}}`)
+	old.Properties["children"] = openapi3.NewArraySchema().WithItems(old).NewRef()
+	new := schema(t, `{"type":"object","properties":{"name":{"type":"integer"}}}`)
+	new.Properties["children"] = openapi3.NewArraySchema().WithItems(new).NewRef()
+	d := &differ{}
+	s := &schemaDiffer{differ: d, request: true}
+	s.compare("", old, new)
+	if len(d.changes) != 1 || d.changes[0].Field != "name" {
+		t.Errorf("got %+v, want the type change of name", d.changes)
+	}
+}
@@ -0,0 +1,107 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// securityLocation is the location of the changes of the security requirements
+const securityLocation = "security"
+
+// effectiveSecurity returns the security requirements of an operation, the ones of the
+// document unless it declares its own, with no requirement meaning no security
+func effectiveSecurity(doc *openapi3.T, operation *openapi3.Operation) openapi3.SecurityRequirements {
+	requirements := doc.Security
+	if operation.Security != nil {
+		requirements = *operation.Security
+	}
+	if len(requirements) == 0 {
+		return openapi3.SecurityRequirements{{}}
+	}
+	return requirements
+}
+
+// security compares the requirements of an operation, one of which a request has to meet.
+// A requirement removed is breaking unless the requests meeting it meet another one left, so
+// adding a scheme or a scope to a requirement, or security to an operation without, is.
+func (d *differ) security(operation string, old openapi3.SecurityRequirements, new openapi3.SecurityRequirements) {
+	for _, requirement := range old {
+		if containsRequirement(new, requirement) {
+			continue
+		}
+		breaking := true
+		for _, other := range new {
+			if satisfies(requirement, other) {
+				breaking = false
+				break
+			}
+		}
+		if len(requirement) == 0 {
+			d.add(breaking, operation, securityLocation, "", "security became required")
+		} else {
+			d.add(breaking, operation, securityLocation, "", "security requirement "+describeRequirement(requirement)+" removed")
+		}
+	}
+	for _, requirement := range new {
+		if containsRequirement(old, requirement) {
+			continue
+		}
+		if len(requirement) == 0 {
+			d.add(false, operation, securityLocation, "", "security became optional")
+		} else {
+			d.add(false, operation, securityLocation, "", "security requirement "+describeRequirement(requirement)+" added")
+		}
+	}
+}
+
+func containsRequirement(requirements openapi3.SecurityRequirements, requirement openapi3.SecurityRequirement) bool {
+	for _, other := range requirements {
+		if describeRequirement(other) == describeRequirement(requirement) {
+			return true
+		}
+	}
+	return false
+}
+
+// satisfies reports whether the requests meeting requirement meet other, which is the case
+// when other asks for some of its schemes and scopes only
+func satisfies(requirement openapi3.SecurityRequirement, other openapi3.SecurityRequirement) bool {
+	for scheme, scopes := range other {
+		granted, ok := requirement[scheme]
+		if !ok {
+			return false
+		}
+		for _, scope := range scopes {
+			if !containsString(granted, scope) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// describeRequirement names the schemes of a requirement with their scopes, like
+// oauth (read, write) and api_key
+func describeRequirement(requirement openapi3.SecurityRequirement) string {
+	schemes := make([]string, 0, len(requirement))
+	for _, scheme := range sortedKeys(requirement) {
+		scopes := append([]string(nil), requirement[scheme]...)
+		sort.Strings(scopes)
+		if len(scopes) > 0 {
+			scheme += " (" + strings.Join(scopes, ", ") + ")"
+		}
+		schemes = append(schemes, scheme)
+	}
+	return strings.Join(schemes, " and ")
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package swagger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/long2ice/fibers/constants"
+	"github.com/long2ice/fibers/types"
+)
+
+var placeholderType = reflect.TypeOf(types.Placeholder{})
+
+// envelope is the type of a model wrapped in an envelope template
+type envelope struct {
+	template reflect.Type
+	model    reflect.Type
+	name     string
+}
+
+// getEnvelopeRef documents model wrapped in the envelope template, as a component named after
+// the template with its placeholder replaced by the model, like Envelope_User, and returns the
+// type of the wrapped model
+func (swagger *Swagger) getEnvelopeRef(template interface{}, model interface{}) (*openapi3.SchemaRef, reflect.Type) {
+	templateType := reflect.TypeOf(template)
+	for templateType.Kind() == reflect.Ptr {
+		templateType = templateType.Elem()
+	}
+	modelType := reflect.TypeOf(model)
+	type_, ok := substitute(templateType, modelType)
+	if !ok || type_.Kind() != reflect.Struct {
+		swagger.addError(fmt.Errorf("envelope %s has no types.Placeholder field", templateType))
+		return swagger.getSchemaRefByType(model, false), modelType
+	}
+	if _, ok := swagger.envelopes[type_]; !ok {
+		base, args := swagger.envelopeArguments(templateType, modelType, unqualified)
+		swagger.envelopes[type_] = envelope{
+			template: templateType,
+			model:    modelType,
+			name:     joinArguments(base, args),
+		}
+	}
+	return swagger.getComponentRef(type_, false), type_
+}
+
+// envelopeArguments names the envelope of a model after the template and its type arguments,
+// which are the model for a template without any, with packages qualified as q
+func (swagger *Swagger) envelopeArguments(template reflect.Type, model reflect.Type, q qualification) (string, []string) {
+	modelName := typeArgumentName(model.String(), q)
+	for model.Kind() == reflect.Ptr {
+		model = model.Elem()
+	}
+	if isComponentType(model) {
+		modelName = swagger.schemaName(model)
+		if q != unqualified {
+			modelName = typeArgumentName(model.PkgPath()+"."+modelName, q)
+		}
+	}
+	base, args := typeArguments(template, q)
+	if len(args) == 0 {
+		args = []string{modelName}
+	}
+	placeholder := typeArgumentName(placeholderType.PkgPath()+"."+placeholderType.Name(), q)
+	for i, arg := range args {
+		args[i] = strings.ReplaceAll(arg, placeholder, modelName)
+	}
+	return base, args
+}
+
+// substitute returns type_ with types.Placeholder replaced by model, reporting whether it
+// has been found. Structs holding it are rebuilt without their unexported fields and with
+// embedded fields tagged embed, which describes them the same.
+func substitute(type_ reflect.Type, model reflect.Type) (reflect.Type, bool) {
+	switch type_.Kind() {
+	case reflect.Ptr:
+		if elem, ok := substitute(type_.Elem(), model); ok {
+			return reflect.PtrTo(elem), true
+		}
+	case reflect.Slice:
+		if elem, ok := substitute(type_.Elem(), model); ok {
+			return reflect.SliceOf(elem), true
+		}
+	case reflect.Array:
+		if elem, ok := substitute(type_.Elem(), model); ok {
+			return reflect.ArrayOf(type_.Len(), elem), true
+		}
+	case reflect.Map:
+		if elem, ok := substitute(type_.Elem(), model); ok {
+			return reflect.MapOf(type_.Key(), elem), true
+		}
+	case reflect.Struct:
+		if type_ == placeholderType {
+			return model, true
+		}
+		found := false
+		fields := make([]reflect.StructField, 0, type_.NumField())
+		for i := 0; i < type_.NumField(); i++ {
+			field := type_.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if fieldType, ok := substitute(field.Type, model); ok {
+				field.Type = fieldType
+				found = true
+			}
+			if field.Anonymous {
+				field.Anonymous = false
+				if name, _, _ := strings.Cut(field.Tag.Get(constants.JSON), ","); name == "" {
+					field.Tag = reflect.StructTag(strings.TrimSpace(string(field.Tag) + ` embed:""`))
+				}
+			}
+			field.Index, field.Offset = nil, 0
+			fields = append(fields, field)
+		}
+		if found {
+			return reflect.StructOf(fields), true
+		}
+	}
+	return type_, false
+}
@@ -0,0 +1,46 @@
+package swagger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RouteError is a problem found while documenting a router
+type RouteError struct {
+	Path   string
+	Method string
+	Err    error
+}
+
+func (e *RouteError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Method, e.Path, e.Err)
+}
+
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is every problem found while building the OpenAPI document
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// addError records err against the router being documented, once per router as a
+// model is documented for both its request and response
+func (swagger *Swagger) addError(err error) {
+	if swagger.path != "" {
+		err = &RouteError{Path: swagger.path, Method: swagger.method, Err: err}
+	}
+	for _, e := range swagger.errors {
+		if e.Error() == err.Error() {
+			return
+		}
+	}
+	swagger.errors = append(swagger.errors, err)
+}
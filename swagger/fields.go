@@ -0,0 +1,66 @@
+package swagger
+
+import (
+	"reflect"
+
+	"github.com/fatih/structtag"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/long2ice/fibers/constants"
+)
+
+// isEmbeddedField reports whether the fields of a struct field are promoted to the struct
+// holding it, which is the case for untagged anonymous structs like in encoding/json, and
+// for fields tagged embed or json:",inline"
+func isEmbeddedField(field reflect.StructField, tags *structtag.Tags) bool {
+	if _, err := tags.Get(constants.EMBED); err == nil {
+		return true
+	}
+	type_ := field.Type
+	for type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	if type_.Kind() != reflect.Struct {
+		return false
+	}
+	for _, key := range []string{constants.FORM, constants.JSON} {
+		if tag, err := tags.Get(key); err == nil {
+			if tag.Name == "" && tag.HasOption("inline") {
+				return true
+			}
+			if tag.Name != "" {
+				return false
+			}
+		}
+	}
+	return field.Anonymous
+}
+
+// isParameterField reports whether a field is bound from the query, path, headers or cookies
+func isParameterField(tags *structtag.Tags) bool {
+	for _, key := range []string{constants.QUERY, constants.URI, constants.HEADER, constants.COOKIE} {
+		if _, err := tags.Get(key); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// promoteFields adds the properties of embedded structs to schema, the fields of the struct
+// itself and of the embedded structs that come first shadow the others as in encoding/json
+func promoteFields(schema *openapi3.Schema, embedded []*openapi3.Schema) {
+	for _, embeddedSchema := range embedded {
+		promoted := make(map[string]bool)
+		for name, property := range embeddedSchema.Properties {
+			if _, ok := schema.Properties[name]; !ok {
+				schema.Properties[name] = property
+				promoted[name] = true
+			}
+		}
+		for _, name := range embeddedSchema.Required {
+			if promoted[name] {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		schema.AllOf = append(schema.AllOf, embeddedSchema.AllOf...)
+	}
+}
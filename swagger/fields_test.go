@@ -0,0 +1,108 @@
+package swagger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/structtag"
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+)
+
+type audit struct {
+	CreatedBy string `json:"created_by" validate:"required"`
+	Name      string `json:"name"`
+}
+
+type pagination struct {
+	Page int `query:"page"`
+}
+
+type note struct {
+	Text string `json:"text"`
+}
+
+type embeddedReq struct {
+	audit
+	pagination `embed:""`
+	Inline     note   `json:",inline"`
+	Named      note   `json:"named"`
+	Secret     string `json:"-"`
+	Name       string `json:"name"`
+}
+
+type embeddedResp struct {
+	*audit
+	Inline note   `json:",inline"`
+	Named  note   `json:"named,omitempty"`
+	Secret string `json:"-"`
+	Name   int    `json:"name"`
+}
+
+func TestIsEmbeddedField(t *testing.T) {
+	type model struct {
+		audit
+		*note
+		Tagged     audit  `json:"tagged"`
+		Inline     audit  `json:",inline"`
+		FormInline audit  `form:",inline"`
+		Embed      audit  `embed:""`
+		Plain      audit  ``
+		Text       string `json:",inline"`
+		TaggedNote note   `json:"note"`
+	}
+	tests := map[string]bool{
+		"audit":      true,
+		"note":       true,
+		"Tagged":     false,
+		"Inline":     true,
+		"FormInline": true,
+		"Embed":      true,
+		"Plain":      false,
+		"Text":       false,
+		"TaggedNote": false,
+	}
+	type_ := reflect.TypeOf(model{})
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			field, _ := type_.FieldByName(name)
+			tags, err := structtag.Parse(string(field.Tag))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := isEmbeddedField(field, tags); got != want {
+				t.Errorf("isEmbeddedField(%s) = %v, want %v", name, got, want)
+			}
+		})
+	}
+}
+
+// fields of embedded structs are promoted unless shadowed, fields tagged json:"-" are left out
+func TestEmbeddedFields(t *testing.T) {
+	doc := document(t, map[string]*router.Router{
+		"POST /notes": router.NewR(func(c *fiber.Ctx, req embeddedReq) (embeddedResp, error) {
+			return embeddedResp{}, nil
+		}),
+	})
+	// note is required in responses only, so requests refer to its input component
+	request := componentSchema(t, doc, "embeddedReq")
+	assertJSON(t, request["properties"], `{
+		"created_by":{"type":"string"},
+		"text":{"type":"string"},
+		"named":{"$ref":"#/components/schemas/noteInput"},
+		"name":{"type":"string"}
+	}`)
+	assertJSON(t, request["required"], `["created_by"]`)
+
+	response := componentSchema(t, doc, "embeddedResp")
+	assertJSON(t, response["properties"], `{
+		"created_by":{"type":"string"},
+		"text":{"type":"string"},
+		"named":{"$ref":"#/components/schemas/note"},
+		"name":{"type":"integer"}
+	}`)
+	assertJSON(t, response["required"], `["name","created_by","text"]`)
+
+	operation := doc["paths"].(map[string]interface{})["/notes"].(map[string]interface{})["post"].(map[string]interface{})
+	assertJSON(t, operation["parameters"], `[{"in":"query","name":"page","schema":{"type":"integer"}}]`)
+}
@@ -0,0 +1,152 @@
+package swagger
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// qualifier matches the package path before a type name, like example.com/api. in example.com/api.User
+var qualifier = regexp.MustCompile(`(?:[\w.-]+/)*[\w-]+\.`)
+
+// qualification is how much of their package the names of type arguments keep
+type qualification int
+
+const (
+	// unqualified names are like User
+	unqualified qualification = iota
+	// packageQualified names are like api.User
+	packageQualified
+	// pathQualified names are like example.com_api.User
+	pathQualified
+)
+
+// TypeArguments splits the name of an instantiated generic type into the name of the generic
+// type and the names of its type arguments without their packages, for instance
+// Envelope[example.com/api.Page[example.com/api.User]] gives Envelope and [Page_User]. It
+// helps a SchemaNamer to name instantiations like PageOfUser.
+func TypeArguments(type_ reflect.Type) (string, []string) {
+	return typeArguments(type_, unqualified)
+}
+
+func typeArguments(type_ reflect.Type, q qualification) (string, []string) {
+	name := type_.Name()
+	start := strings.Index(name, "[")
+	if start < 0 || !strings.HasSuffix(name, "]") {
+		return name, nil
+	}
+	var args []string
+	depth, from := 0, start+1
+	for i := from; i < len(name)-1; i++ {
+		switch name[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, typeArgumentName(name[from:i], q))
+				from = i + 1
+			}
+		}
+	}
+	args = append(args, typeArgumentName(name[from:len(name)-1], q))
+	return name[:start], args
+}
+
+// typeArgumentName names a type after its name with its packages qualified as q, slices are
+// named ListOf their items and pointers like the type they point to
+func typeArgumentName(name string, q qualification) string {
+	name = strings.TrimSpace(name)
+	switch q {
+	case unqualified:
+		name = qualifier.ReplaceAllString(name, "")
+	case packageQualified:
+		name = qualifier.ReplaceAllStringFunc(name, func(pkg string) string {
+			return path.Base(strings.TrimSuffix(pkg, ".")) + "."
+		})
+	}
+	name = strings.ReplaceAll(name, "[]", "ListOf")
+	name = strings.ReplaceAll(name, "*", "")
+	return strings.Trim(invalidName.ReplaceAllString(name, "_"), "_")
+}
+
+// genericName names an instantiated generic type after the generic type and its arguments,
+// such as Envelope_User
+func genericName(type_ reflect.Type) (string, bool) {
+	base, args := TypeArguments(type_)
+	if len(args) == 0 {
+		return "", false
+	}
+	return joinArguments(base, args), true
+}
+
+func joinArguments(base string, args []string) string {
+	return invalidName.ReplaceAllString(base, "_") + "_" + strings.Join(args, "_")
+}
+
+// instantiation returns the generic type and the arguments of an instantiation or an envelope,
+// qualified as q
+func (swagger *Swagger) instantiation(type_ reflect.Type, q qualification) (string, []string, bool) {
+	if swagger.SchemaNamer != nil {
+		return "", nil, false
+	}
+	if envelope, ok := swagger.envelopes[type_]; ok {
+		base, args := swagger.envelopeArguments(envelope.template, envelope.model, q)
+		return base, args, true
+	}
+	base, args := typeArguments(type_, q)
+	return base, args, len(args) > 0
+}
+
+// qualifyArguments names instantiations of the same generic type whose type arguments have the
+// same names, such as Page[p1.User] and Page[p2.User], by qualifying the arguments that tell
+// them apart with their package, like Page_p1.User and Page_p2.User, or with the full package
+// path if needed. It reports false if they are not such instantiations.
+func (swagger *Swagger) qualifyArguments(types []reflect.Type) (map[reflect.Type]string, bool) {
+	args := make([][pathQualified + 1][]string, len(types))
+	var firstBase string
+	for i, type_ := range types {
+		for q := unqualified; q <= pathQualified; q++ {
+			base, typeArgs, ok := swagger.instantiation(type_, q)
+			if !ok || i > 0 && (base != firstBase || len(typeArgs) != len(args[0][q])) {
+				return nil, false
+			}
+			firstBase = base
+			args[i][q] = typeArgs
+		}
+	}
+	// the argument at each position keeps the least of its package that tells the types apart
+	qualifications := make([]qualification, len(args[0][unqualified]))
+	for q := packageQualified; q <= pathQualified; q++ {
+		for position := range qualifications {
+			if distinct(args, position, q) > distinct(args, position, qualifications[position]) {
+				qualifications[position] = q
+			}
+		}
+		names := make(map[reflect.Type]string, len(types))
+		seen := make(map[string]bool, len(types))
+		for i, type_ := range types {
+			typeArgs := make([]string, len(qualifications))
+			for position, q := range qualifications {
+				typeArgs[position] = args[i][q][position]
+			}
+			names[type_] = joinArguments(firstBase, typeArgs)
+			seen[names[type_]] = true
+		}
+		if len(seen) == len(types) {
+			return names, true
+		}
+	}
+	return nil, false
+}
+
+// distinct counts the names of the argument at position qualified as q
+func distinct(args [][pathQualified + 1][]string, position int, q qualification) int {
+	names := make(map[string]bool, len(args))
+	for _, typeArgs := range args {
+		names[typeArgs[q][position]] = true
+	}
+	return len(names)
+}
@@ -0,0 +1,93 @@
+// Package walk visits the schemas of an OpenAPI document decoded from JSON or YAML, which is
+// how documents are converted between 3.0 and 3.1.
+package walk
+
+// Schemas calls f with each schema of the components and the operations of doc, but not with
+// their subschemas, which f reaches with Subschemas
+func Schemas(doc map[string]interface{}, f func(map[string]interface{})) {
+	parameter := func(parameter map[string]interface{}) { Parameter(parameter, f) }
+	response := func(response map[string]interface{}) { Response(response, f) }
+	content := func(object map[string]interface{}) { Content(object, f) }
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		Values(components["schemas"], f)
+		Values(components["parameters"], parameter)
+		Values(components["headers"], parameter)
+		Values(components["requestBodies"], content)
+		Values(components["responses"], response)
+	}
+	Values(doc["paths"], func(pathItem map[string]interface{}) {
+		for key, value := range pathItem {
+			if key == "parameters" {
+				Items(value, parameter)
+				continue
+			}
+			operation, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			Items(operation["parameters"], parameter)
+			if requestBody, ok := operation["requestBody"].(map[string]interface{}); ok {
+				content(requestBody)
+			}
+			Values(operation["responses"], response)
+		}
+	})
+}
+
+// Subschemas calls f with the schemas nested in schema one level down
+func Subschemas(schema map[string]interface{}, f func(map[string]interface{})) {
+	Values(schema["properties"], f)
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		if subschema, ok := schema[key].(map[string]interface{}); ok {
+			f(subschema)
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		Items(schema[key], f)
+	}
+}
+
+// Response calls f with the schemas of the headers and the content of a response
+func Response(response map[string]interface{}, f func(map[string]interface{})) {
+	Values(response["headers"], func(header map[string]interface{}) { Parameter(header, f) })
+	Content(response, f)
+}
+
+// Parameter calls f with the schema of a parameter or a header, and of its content
+func Parameter(parameter map[string]interface{}, f func(map[string]interface{})) {
+	if schema, ok := parameter["schema"].(map[string]interface{}); ok {
+		f(schema)
+	}
+	Content(parameter, f)
+}
+
+// Content calls f with the schemas of the media types of the content of object
+func Content(object map[string]interface{}, f func(map[string]interface{})) {
+	Values(object["content"], func(mediaType map[string]interface{}) {
+		if schema, ok := mediaType["schema"].(map[string]interface{}); ok {
+			f(schema)
+		}
+	})
+}
+
+// Values calls f with the objects that are values of the object value
+func Values(value interface{}, f func(map[string]interface{})) {
+	if object, ok := value.(map[string]interface{}); ok {
+		for _, item := range object {
+			if item, ok := item.(map[string]interface{}); ok {
+				f(item)
+			}
+		}
+	}
+}
+
+// Items calls f with the objects that are items of the array value
+func Items(value interface{}, f func(map[string]interface{})) {
+	if array, ok := value.([]interface{}); ok {
+		for _, item := range array {
+			if item, ok := item.(map[string]interface{}); ok {
+				f(item)
+			}
+		}
+	}
+}
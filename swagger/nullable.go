@@ -0,0 +1,43 @@
+package swagger
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/long2ice/fibers/types"
+)
+
+// sqlNullValues maps the database/sql null types to their value
+var sqlNullValues = map[reflect.Type]interface{}{
+	reflect.TypeOf(sql.NullString{}):  "",
+	reflect.TypeOf(sql.NullInt64{}):   int64(0),
+	reflect.TypeOf(sql.NullInt32{}):   int32(0),
+	reflect.TypeOf(sql.NullInt16{}):   int16(0),
+	reflect.TypeOf(sql.NullByte{}):    uint8(0),
+	reflect.TypeOf(sql.NullFloat64{}): float64(0),
+	reflect.TypeOf(sql.NullBool{}):    false,
+	reflect.TypeOf(sql.NullTime{}):    time.Time{},
+}
+
+// nullableValue returns the value wrapped by types documented as a nullable value
+func nullableValue(t interface{}) (interface{}, bool) {
+	if n, ok := t.(types.Nullable); ok {
+		return n.NullableValue(), true
+	}
+	value, ok := sqlNullValues[reflect.TypeOf(t)]
+	return value, ok
+}
+
+// isNullableField reports whether a field is a pointer that is marshaled as null when nil
+func isNullableField(field reflect.StructField) bool {
+	return field.Type.Kind() == reflect.Ptr && field.Type.Elem() != fileHeaderType
+}
+
+// nullable marks a schema as accepting null
+func nullable(ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	ref = inline(ref)
+	ref.Value.Nullable = true
+	return ref
+}
@@ -0,0 +1,78 @@
+package swagger
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+	"github.com/long2ice/fibers/types"
+)
+
+type nullableModel struct {
+	Nickname *string               `json:"nickname"`
+	Friend   *user                 `json:"friend"`
+	Note     sql.NullString        `json:"note"`
+	Count    sql.NullInt64         `json:"count"`
+	At       sql.NullTime          `json:"at"`
+	Age      types.Optional[int]   `json:"age"`
+	Owner    types.Optional[*user] `json:"owner"`
+	Name     string                `json:"name"`
+}
+
+func TestNullable(t *testing.T) {
+	doc := document(t, map[string]*router.Router{
+		"POST /nullable": router.New(func(c *fiber.Ctx, req nullableModel) error { return nil }),
+	})
+	tests := map[string]string{
+		"nickname": `{"type":"string","nullable":true}`,
+		"friend":   `{"allOf":[{"$ref":"#/components/schemas/user"}],"nullable":true}`,
+		"note":     `{"type":"string","nullable":true}`,
+		"count":    `{"type":"integer","format":"int64","nullable":true}`,
+		"at":       `{"type":"string","format":"date-time","nullable":true}`,
+		"age":      `{"type":"integer","nullable":true}`,
+		"owner":    `{"allOf":[{"$ref":"#/components/schemas/user"}],"nullable":true}`,
+		"name":     `{"type":"string"}`,
+	}
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertJSON(t, property(t, doc, "nullableModel", name), want)
+		})
+	}
+}
+
+type requiredModel struct {
+	Name     string  `json:"name"`
+	Nickname string  `json:"nickname,omitempty"`
+	Friend   *user   `json:"friend"`
+	Email    *string `json:"email,omitempty"`
+	Untagged string
+	Ignored  string `json:"-"`
+	Dash     string `json:"-,"`
+}
+
+type requiredReq struct {
+	Name string `json:"name"`
+	Age  int    `json:"age" validate:"required"`
+}
+
+// response fields are written by encoding/json unless they are omitted when empty, so they
+// are required while request fields are only required by their validate rules
+func TestRequiredFields(t *testing.T) {
+	doc := document(t, map[string]*router.Router{
+		"POST /users": router.NewR(func(c *fiber.Ctx, req requiredReq) (requiredModel, error) {
+			return requiredModel{}, nil
+		}),
+	})
+	response := componentSchema(t, doc, "requiredModel")
+	assertJSON(t, response["required"], `["name","friend","Untagged","-"]`)
+	assertJSON(t, response["properties"], `{
+		"name":{"type":"string"},
+		"nickname":{"type":"string"},
+		"friend":{"allOf":[{"$ref":"#/components/schemas/user"}],"nullable":true},
+		"email":{"type":"string","nullable":true},
+		"Untagged":{"type":"string"},
+		"-":{"type":"string"}
+	}`)
+	assertJSON(t, componentSchema(t, doc, "requiredReq")["required"], `["age"]`)
+}
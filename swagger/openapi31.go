@@ -0,0 +1,102 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/long2ice/fibers/swagger/internal/walk"
+)
+
+const (
+	// OpenAPI30 is the default version of the documents
+	OpenAPI30 = "3.0.0"
+	// OpenAPI31 writes documents whose schemas are JSON Schema 2020-12
+	OpenAPI31 = "3.1.0"
+)
+
+// annotations are the schema keywords that describe a nullable schema as a whole
+var annotations = map[string]bool{
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+	"deprecated":  true,
+	"readOnly":    true,
+	"writeOnly":   true,
+}
+
+// marshalOpenAPI31 converts a 3.0 document to 3.1, the document is built as 3.0 for the
+// request and response validation and its schemas are rewritten when it is written
+func marshalOpenAPI31(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep integers such as int64 examples exact
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	doc["openapi"] = OpenAPI31
+	walk.Schemas(doc, convertSchema)
+	return json.Marshal(doc)
+}
+
+// convertSchema rewrites the 3.0 keywords of a schema and its subschemas that changed in 3.1
+func convertSchema(schema map[string]interface{}) {
+	walk.Subschemas(schema, convertSchema)
+	for key, exclusive := range map[string]string{"minimum": "exclusiveMinimum", "maximum": "exclusiveMaximum"} {
+		if schema[exclusive] == true {
+			schema[exclusive] = schema[key]
+			delete(schema, key)
+		} else {
+			delete(schema, exclusive)
+		}
+	}
+	if example, ok := schema["example"]; ok {
+		schema["examples"] = []interface{}{example}
+		delete(schema, "example")
+	}
+	if schema["type"] == "string" {
+		switch schema["format"] {
+		case "binary":
+			schema["contentMediaType"] = "application/octet-stream"
+			delete(schema, "format")
+		case "byte":
+			schema["contentEncoding"] = "base64"
+			delete(schema, "format")
+		}
+	}
+	if schema["nullable"] == true {
+		withNull(schema)
+	}
+	delete(schema, "nullable")
+}
+
+// withNull lets a schema accept null, through the type if it has one and otherwise by
+// moving its keywords into an anyOf next to the null type
+func withNull(schema map[string]interface{}) {
+	delete(schema, "nullable")
+	if type_, ok := schema["type"].(string); ok {
+		schema["type"] = []interface{}{type_, "null"}
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			schema["enum"] = append(enum, nil)
+		}
+		return
+	}
+	inner := make(map[string]interface{})
+	for key, value := range schema {
+		if !annotations[key] {
+			inner[key] = value
+			delete(schema, key)
+		}
+	}
+	// schemas without keywords already accept null
+	if len(inner) == 0 {
+		return
+	}
+	var nonNull interface{} = inner
+	// a reference wrapped in allOf to sit next to nullable needs no wrapping anymore
+	if allOf, ok := inner["allOf"].([]interface{}); ok && len(inner) == 1 && len(allOf) == 1 {
+		nonNull = allOf[0]
+	}
+	schema["anyOf"] = []interface{}{nonNull, map[string]interface{}{"type": "null"}}
+}
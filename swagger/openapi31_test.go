@@ -0,0 +1,111 @@
+package swagger
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+)
+
+type conformanceAddress struct {
+	City string `json:"city"`
+}
+
+type conformanceReq struct {
+	Nickname *string                 `json:"nickname"`
+	Address  *conformanceAddress     `json:"address"`
+	Age      int                     `json:"age" validate:"gt=0,lt=150" example:"30"`
+	Avatar   *multipart.FileHeader   `json:"avatar"`
+	Photos   []*multipart.FileHeader `json:"photos"`
+	Data     []byte                  `json:"data"`
+}
+
+// conformanceDocument builds a document with nullable fields, exclusive bounds, an example and
+// file uploads written as version
+func conformanceDocument(t *testing.T, version string) map[string]interface{} {
+	t.Helper()
+	swagger := New("test", "test", "1.0.0", OpenAPIVersion(version))
+	r := router.New(func(c *fiber.Ctx, req conformanceReq) error { return nil })
+	r.Path, r.Method = "/users", fiber.MethodPost
+	swagger.Routers = map[string]map[string]*router.Router{"/users": {fiber.MethodPost: r}}
+	if err := swagger.BuildOpenAPI(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := swagger.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err = json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestOpenAPIVersions(t *testing.T) {
+	tests := []struct {
+		version    string
+		properties map[string]string
+	}{
+		{OpenAPI30, map[string]string{
+			"nickname": `{"type":"string","nullable":true}`,
+			"address":  `{"allOf":[{"$ref":"#/components/schemas/conformanceAddress"}],"nullable":true}`,
+			"age": `{"type":"integer","minimum":0,"exclusiveMinimum":true,"maximum":150,"exclusiveMaximum":true,` +
+				`"example":30}`,
+			"avatar": `{"type":"string","format":"binary"}`,
+			"photos": `{"type":"array","items":{"type":"string","format":"binary"}}`,
+			"data":   `{"type":"string","format":"byte"}`,
+		}},
+		{OpenAPI31, map[string]string{
+			"nickname": `{"type":["string","null"]}`,
+			"address":  `{"anyOf":[{"$ref":"#/components/schemas/conformanceAddress"},{"type":"null"}]}`,
+			"age":      `{"type":"integer","exclusiveMinimum":0,"exclusiveMaximum":150,"examples":[30]}`,
+			"avatar":   `{"type":"string","contentMediaType":"application/octet-stream"}`,
+			"photos":   `{"type":"array","items":{"type":"string","contentMediaType":"application/octet-stream"}}`,
+			"data":     `{"type":"string","contentEncoding":"base64"}`,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			doc := conformanceDocument(t, test.version)
+			if doc["openapi"] != test.version {
+				t.Errorf("openapi = %v, want %s", doc["openapi"], test.version)
+			}
+			schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+			properties := schemas["conformanceReq"].(map[string]interface{})["properties"].(map[string]interface{})
+			for name, want := range test.properties {
+				t.Run(name, func(t *testing.T) {
+					assertJSON(t, properties[name], want)
+				})
+			}
+		})
+	}
+}
+
+func TestOpenAPI31Keywords(t *testing.T) {
+	// keywords of 3.0 that 3.1 replaces are left nowhere in the document
+	removed := map[string]bool{"nullable": true, "example": true}
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			for key, item := range value {
+				if removed[key] {
+					t.Errorf("%s has %s", path, key)
+				}
+				if (key == "exclusiveMinimum" || key == "exclusiveMaximum") && reflect.TypeOf(item).Kind() == reflect.Bool {
+					t.Errorf("%s.%s is a boolean", path, key)
+				}
+				walk(path+"."+key, item)
+			}
+		case []interface{}:
+			for _, item := range value {
+				walk(path+"[]", item)
+			}
+		}
+	}
+	walk("", conformanceDocument(t, OpenAPI31))
+}
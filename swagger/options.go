@@ -1,6 +1,8 @@
 package swagger
 
 import (
+	"reflect"
+
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
@@ -42,6 +44,13 @@ func OpenAPIUrl(url string) Option {
 	}
 }
 
+// OpenAPIYAMLUrl set where the document is served as YAML, an empty url serves it only as JSON
+func OpenAPIYAMLUrl(url string) Option {
+	return func(swagger *Swagger) {
+		swagger.OpenAPIYAMLUrl = url
+	}
+}
+
 func Servers(servers openapi3.Servers) Option {
 	return func(swagger *Swagger) {
 		swagger.Servers = servers
@@ -77,3 +86,24 @@ func RedocOptions(options map[string]interface{}) Option {
 		swagger.RedocOptions = options
 	}
 }
+
+// SchemaNamer sets how component schemas are named, defaulting to the Go type name
+func SchemaNamer(namer func(type_ reflect.Type) string) Option {
+	return func(swagger *Swagger) {
+		swagger.SchemaNamer = namer
+	}
+}
+
+// TypeSchema documents the type of value with schema, for types that can't implement SchemaProvider
+func TypeSchema(value interface{}, schema *openapi3.Schema) Option {
+	return func(swagger *Swagger) {
+		swagger.RegisterSchema(value, schema)
+	}
+}
+
+// OpenAPIVersion set the version of the written document, OpenAPI30 or OpenAPI31
+func OpenAPIVersion(version string) Option {
+	return func(swagger *Swagger) {
+		swagger.OpenAPIVersion = version
+	}
+}
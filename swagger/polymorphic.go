@@ -0,0 +1,52 @@
+package swagger
+
+import (
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/long2ice/fibers/types"
+)
+
+// mapping is a discriminator value waiting for the final name of its component
+type mapping struct {
+	discriminator *openapi3.Discriminator
+	value         string
+	ref           *openapi3.SchemaRef
+}
+
+// getPolymorphicSchema documents a types.OneOf as oneOf its variants with a discriminator
+func (swagger *Swagger) getPolymorphicSchema(polymorphic types.Polymorphic, request bool) *openapi3.Schema {
+	property, variants := polymorphic.Variants()
+	if len(variants) == 0 {
+		swagger.addError(fmt.Errorf("%T: no variants declared", polymorphic))
+		return newAnySchema()
+	}
+	schema := &openapi3.Schema{
+		Discriminator: &openapi3.Discriminator{PropertyName: property, Mapping: make(map[string]string)},
+	}
+	documented := make(map[*openapi3.Schema]bool)
+	for _, variant := range variants {
+		ref := swagger.getSchemaRefByType(variant.Type, request)
+		if ref.Ref == "" {
+			swagger.addError(fmt.Errorf("%T: variant %T must be a named struct", polymorphic, variant.Type))
+			continue
+		}
+		if _, ok := ref.Value.Properties[property]; !ok {
+			swagger.addError(fmt.Errorf("%T: variant %T has no %s property", polymorphic, variant.Type, property))
+		}
+		// a type declared under several values is one of the variants once
+		if !documented[ref.Value] {
+			documented[ref.Value] = true
+			schema.OneOf = append(schema.OneOf, ref)
+		}
+		swagger.mappings = append(swagger.mappings, mapping{discriminator: schema.Discriminator, value: variant.Key(), ref: ref})
+	}
+	return schema
+}
+
+// buildMappings points discriminator values at the named components
+func (swagger *Swagger) buildMappings() {
+	for _, m := range swagger.mappings {
+		m.discriminator.Mapping[m.value] = m.ref.Ref
+	}
+}
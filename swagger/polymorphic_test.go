@@ -0,0 +1,120 @@
+package swagger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+	"github.com/long2ice/fibers/types"
+)
+
+type pet interface{ isPet() }
+
+type cat struct {
+	Kind  string `json:"kind"`
+	Lives int    `json:"lives"`
+}
+
+type dog struct {
+	Kind  string `json:"kind"`
+	Breed string `json:"breed"`
+}
+
+func (cat) isPet()  {}
+func (*dog) isPet() {}
+
+type petVariants struct{}
+
+func (petVariants) Variants() (string, []types.Variant) {
+	return "kind", []types.Variant{
+		{Value: "cat", Type: cat{}},
+		{Value: "kitten", Type: cat{}},
+		{Value: "dog", Type: &dog{}},
+	}
+}
+
+type shelter struct {
+	Version int `json:"version"`
+}
+
+type shelterVariants struct{}
+
+func (shelterVariants) Variants() (string, []types.Variant) {
+	return "version", []types.Variant{{Value: 1, Type: shelter{}}}
+}
+
+type adoption struct {
+	Pet     types.OneOf[pet, petVariants]             `json:"pet"`
+	Shelter types.OneOf[interface{}, shelterVariants] `json:"shelter"`
+}
+
+func TestPolymorphicSchema(t *testing.T) {
+	doc := document(t, map[string]*router.Router{
+		"POST /adoptions": router.New(func(c *fiber.Ctx, req adoption) error { return nil }),
+	})
+	tests := map[string]string{
+		// a type declared under several values is one of the variants once
+		"pet": `{"oneOf":[{"$ref":"#/components/schemas/cat"},{"$ref":"#/components/schemas/dog"}],` +
+			`"discriminator":{"propertyName":"kind","mapping":{"cat":"#/components/schemas/cat",` +
+			`"kitten":"#/components/schemas/cat","dog":"#/components/schemas/dog"}}}`,
+		// non-string values are mapped by their JSON literal
+		"shelter": `{"oneOf":[{"$ref":"#/components/schemas/shelter"}],` +
+			`"discriminator":{"propertyName":"version","mapping":{"1":"#/components/schemas/shelter"}}}`,
+	}
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertJSON(t, property(t, doc, "adoption", name), want)
+		})
+	}
+}
+
+type noVariants struct{}
+
+func (noVariants) Variants() (string, []types.Variant) {
+	return "kind", nil
+}
+
+type missingProperty struct{}
+
+func (missingProperty) Variants() (string, []types.Variant) {
+	return "type", []types.Variant{{Value: "cat", Type: cat{}}}
+}
+
+type unnamedVariant struct{}
+
+func (unnamedVariant) Variants() (string, []types.Variant) {
+	return "kind", []types.Variant{{Value: "name", Type: ""}}
+}
+
+func TestPolymorphicSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		router *router.Router
+		want   string
+	}{
+		{"no variants", router.New(func(c *fiber.Ctx, req struct {
+			Pet types.OneOf[pet, noVariants] `json:"pet"`
+		}) error {
+			return nil
+		}), "no variants declared"},
+		{"missing property", router.New(func(c *fiber.Ctx, req struct {
+			Pet types.OneOf[pet, missingProperty] `json:"pet"`
+		}) error {
+			return nil
+		}), "has no type property"},
+		{"unnamed variant", router.New(func(c *fiber.Ctx, req struct {
+			Pet types.OneOf[interface{}, unnamedVariant] `json:"pet"`
+		}) error {
+			return nil
+		}), "must be a named struct"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := buildRouters(map[string]*router.Router{"POST /pets": test.router})
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package swagger
+
+import (
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SchemaProvider is implemented by types that document themselves, such as decimals
+// written as strings
+type SchemaProvider interface {
+	OpenAPISchema() *openapi3.Schema
+}
+
+var schemaProviderType = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+
+// RegisterSchema documents the type of value with schema, for types that can't implement SchemaProvider
+func (swagger *Swagger) RegisterSchema(value interface{}, schema *openapi3.Schema) {
+	if swagger.typeSchemas == nil {
+		swagger.typeSchemas = make(map[reflect.Type]*openapi3.Schema)
+	}
+	type_ := reflect.TypeOf(value)
+	for type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	swagger.typeSchemas[type_] = schema
+}
+
+// getProvidedSchema returns a copy of the schema registered for or provided by type_, as
+// it may be changed by the tags of the field using it
+func (swagger *Swagger) getProvidedSchema(type_ reflect.Type) (*openapi3.Schema, bool) {
+	schema, ok := swagger.typeSchemas[type_]
+	if !ok && reflect.PtrTo(type_).Implements(schemaProviderType) {
+		schema, ok = reflect.New(type_).Interface().(SchemaProvider).OpenAPISchema(), true
+	}
+	if !ok || schema == nil {
+		return nil, false
+	}
+	copied := *schema
+	copied.AllOf = append(openapi3.SchemaRefs(nil), schema.AllOf...)
+	return &copied, true
+}
@@ -0,0 +1,56 @@
+package swagger
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+)
+
+type money struct {
+	cents int64
+}
+
+func (money) OpenAPISchema() *openapi3.Schema {
+	return openapi3.NewStringSchema().WithPattern(`^\d+\.\d{2}$`)
+}
+
+// decimal stands for a type of another package, which can't implement SchemaProvider
+type decimal struct {
+	value []byte
+}
+
+type providedModel struct {
+	Price    money     `json:"price"`
+	Discount *money    `json:"discount"`
+	Amount   decimal   `json:"amount" description:"amount paid"`
+	Amounts  []decimal `json:"amounts"`
+}
+
+func TestProvidedSchemas(t *testing.T) {
+	registered := openapi3.NewStringSchema().WithFormat("decimal")
+	swagger := New("test", "test", "1.0.0", TypeSchema(&decimal{}, registered))
+	r := router.New(func(c *fiber.Ctx, req providedModel) error { return nil })
+	r.Path, r.Method = "/prices", fiber.MethodPost
+	swagger.Routers = map[string]map[string]*router.Router{"/prices": {fiber.MethodPost: r}}
+	if err := swagger.BuildOpenAPI(); err != nil {
+		t.Fatal(err)
+	}
+	properties := swagger.OpenAPI.Components.Schemas["providedModel"].Value.Properties
+	tests := map[string]string{
+		"price":    `{"type":"string","pattern":"^\\d+\\.\\d{2}$"}`,
+		"discount": `{"type":"string","pattern":"^\\d+\\.\\d{2}$","nullable":true}`,
+		"amount":   `{"type":"string","format":"decimal","description":"amount paid"}`,
+		"amounts":  `{"type":"array","items":{"type":"string","format":"decimal"}}`,
+	}
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertJSON(t, properties[name].Value, want)
+		})
+	}
+	// tags of the fields change copies of the registered schema
+	if registered.Description != "" || registered.Nullable {
+		t.Errorf("registered schema changed to %+v", registered)
+	}
+}
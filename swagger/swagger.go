@@ -1,12 +1,15 @@
 package swagger
 
 import (
+	"encoding"
+	"encoding/json"
+	"fmt"
 	"github.com/google/uuid"
 	"mime/multipart"
 	"net/http"
 	"reflect"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,7 +19,8 @@ import (
 	"github.com/long2ice/fibers/constants"
 	"github.com/long2ice/fibers/router"
 	"github.com/long2ice/fibers/security"
-	log "github.com/sirupsen/logrus"
+	"github.com/long2ice/fibers/types"
+	"gopkg.in/yaml.v3"
 )
 
 type Swagger struct {
@@ -26,6 +30,7 @@ type Swagger struct {
 	DocsUrl        string
 	RedocUrl       string
 	OpenAPIUrl     string
+	OpenAPIYAMLUrl string
 	Routers        map[string]map[string]*router.Router
 	Servers        openapi3.Servers
 	TermsOfService string
@@ -34,16 +39,31 @@ type Swagger struct {
 	OpenAPI        *openapi3.T
 	SwaggerOptions map[string]interface{}
 	RedocOptions   map[string]interface{}
+	Validator      *router.Validator
+	SchemaNamer    func(type_ reflect.Type) string
+	OpenAPIVersion string
+	components     map[componentKey]*component
+	building       []*component
+	inlining       map[reflect.Type]bool
+	envelopes      map[reflect.Type]envelope
+	typeSchemas    map[reflect.Type]*openapi3.Schema
+	mappings       []mapping
+	errors         Errors
+	path           string
+	method         string
 }
 
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
 func New(title, description, version string, options ...Option) *Swagger {
 	swagger := &Swagger{
-		Title:       title,
-		Description: description,
-		Version:     version,
-		DocsUrl:     "/docs",
-		RedocUrl:    "/redoc",
-		OpenAPIUrl:  "/openapi.json",
+		Title:          title,
+		Description:    description,
+		Version:        version,
+		DocsUrl:        "/docs",
+		RedocUrl:       "/redoc",
+		OpenAPIUrl:     "/openapi.json",
+		OpenAPIYAMLUrl: "/openapi.yaml",
 	}
 	for _, option := range options {
 		option(swagger)
@@ -97,6 +117,8 @@ func (swagger *Swagger) getSchemaByType(t interface{}, request bool) *openapi3.S
 		schema = openapi3.NewBoolSchema()
 	case []byte:
 		schema = openapi3.NewBytesSchema()
+	case json.RawMessage, *json.RawMessage:
+		schema = newAnySchema()
 	case *multipart.FileHeader:
 		schema = openapi3.NewStringSchema()
 		schema.Format = "binary"
@@ -108,7 +130,13 @@ func (swagger *Swagger) getSchemaByType(t interface{}, request bool) *openapi3.S
 				Format: "binary",
 			},
 		}
+	case nil:
+		schema = newAnySchema()
 	default:
+		// named types of basic kinds, such as enums
+		if schema = getSchemaByKind(reflect.TypeOf(t)); schema != nil {
+			break
+		}
 		if request {
 			schema = swagger.getRequestSchemaByModel(t)
 		} else {
@@ -118,6 +146,102 @@ func (swagger *Swagger) getSchemaByType(t interface{}, request bool) *openapi3.S
 	return schema
 }
 
+// getSchemaByKind documents types by their kind, returning nil for composite kinds
+func getSchemaByKind(type_ reflect.Type) *openapi3.Schema {
+	for type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	switch type_.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return openapi3.NewIntegerSchema()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return openapi3.NewIntegerSchema().WithMin(0)
+	case reflect.Float32, reflect.Float64:
+		return openapi3.NewFloat64Schema()
+	case reflect.String:
+		return openapi3.NewStringSchema()
+	case reflect.Bool:
+		return openapi3.NewBoolSchema()
+	}
+	return nil
+}
+
+// getSchemaRefByType references the component of named structs and inlines other types,
+// unless they refer to themselves like type Tree []Tree
+func (swagger *Swagger) getSchemaRefByType(t interface{}, request bool) *openapi3.SchemaRef {
+	type_ := reflect.TypeOf(t)
+	for type_ != nil && type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	if type_ != nil {
+		if schema, ok := swagger.getProvidedSchema(type_); ok {
+			return openapi3.NewSchemaRef("", schema)
+		}
+		if polymorphic, ok := reflect.New(type_).Elem().Interface().(types.Polymorphic); ok {
+			return openapi3.NewSchemaRef("", swagger.getPolymorphicSchema(polymorphic, request))
+		}
+		if value, ok := nullableValue(reflect.New(type_).Elem().Interface()); ok {
+			return nullable(swagger.getSchemaRefByType(value, request))
+		}
+		if isComponentType(type_) || swagger.inlining[type_] {
+			return swagger.getComponentRef(type_, request)
+		}
+		if type_.Name() != "" {
+			swagger.inlining[type_] = true
+			schema := swagger.getSchemaByType(t, request)
+			delete(swagger.inlining, type_)
+			if _, ok := swagger.components[componentKey{type_: type_, request: request}]; ok {
+				return swagger.getComponentRef(type_, request)
+			}
+			return openapi3.NewSchemaRef("", schema)
+		}
+	}
+	return openapi3.NewSchemaRef("", swagger.getSchemaByType(t, request))
+}
+
+// withFieldTags sets the description, default and example tags of a field on its schema
+func (swagger *Swagger) withFieldTags(
+	ref *openapi3.SchemaRef,
+	field reflect.StructField,
+	tags *structtag.Tags,
+) *openapi3.SchemaRef {
+	descriptionTag, descriptionErr := tags.Get(constants.DESCRIPTION)
+	defaultTag, defaultErr := tags.Get(constants.DEFAULT)
+	exampleTag, exampleErr := tags.Get(constants.EXAMPLE)
+	if descriptionErr != nil && defaultErr != nil && exampleErr != nil {
+		return ref
+	}
+	ref = inline(ref)
+	if descriptionErr == nil {
+		ref.Value.Description = descriptionTag.Name
+	}
+	if defaultErr == nil {
+		ref.Value.Default = swagger.getTagValue(field, defaultTag)
+	}
+	if exampleErr == nil {
+		ref.Value.Example = swagger.getTagValue(field, exampleTag)
+	}
+	return ref
+}
+
+// withCodecSchema applies the schema hint of a codec for the body of type_, inlining the result if
+// it replaces a component
+func (swagger *Swagger) withCodecSchema(
+	ref *openapi3.SchemaRef,
+	r *router.Router,
+	contentType string,
+	type_ reflect.Type,
+) *openapi3.SchemaRef {
+	bodyCodec, ok := r.Codec(contentType)
+	if !ok {
+		return ref
+	}
+	if schema := bodyCodec.Schema(ref.Value, type_); schema != ref.Value {
+		return openapi3.NewSchemaRef("", schema)
+	}
+	return ref
+}
+
 func (swagger *Swagger) getRequestSchemaByModel(model interface{}) *openapi3.Schema {
 	type_ := reflect.TypeOf(model)
 	value_ := reflect.ValueOf(model)
@@ -126,87 +250,90 @@ func (swagger *Swagger) getRequestSchemaByModel(model interface{}) *openapi3.Sch
 		type_ = type_.Elem()
 	}
 	if value_.Kind() == reflect.Ptr {
-		value_ = value_.Elem()
+		if value_.IsNil() {
+			value_ = reflect.New(type_).Elem()
+		} else {
+			value_ = value_.Elem()
+		}
 	}
 	if type_.Kind() == reflect.Struct {
+		var embedded []*openapi3.Schema
 		for i := 0; i < type_.NumField(); i++ {
 			field := type_.Field(i)
-			value := value_.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
 			tags, err := structtag.Parse(string(field.Tag))
 			if err != nil {
-				log.Fatal(err)
+				swagger.addError(fmt.Errorf("%s.%s: %w", type_, field.Name, err))
+				continue
 			}
-			_, err = tags.Get(constants.EMBED)
-			if err == nil {
-				embedSchema := swagger.getRequestSchemaByModel(value.Interface())
-				for key, embedProperty := range embedSchema.Properties {
-					schema.Properties[key] = embedProperty
-				}
-				for _, name := range embedSchema.Required {
-					schema.Required = append(schema.Required, name)
-				}
+			if isEmbeddedField(field, tags) {
+				embedded = append(embedded, swagger.getRequestSchemaByModel(reflect.Zero(field.Type).Interface()))
+				continue
 			}
+			if !field.IsExported() {
+				continue
+			}
+			value := value_.Field(i)
 			tag, err := tags.Get(constants.FORM)
 			if err != nil {
 				tag, err = tags.Get(constants.JSON)
-				if err != nil {
+			}
+			if err != nil {
+				// fields bound from parameters are not part of the body
+				if isParameterField(tags) {
 					continue
 				}
+				tag = &structtag.Tag{Key: constants.JSON, Name: field.Name}
 			}
-			fieldSchema := swagger.getSchemaByType(value.Interface(), true)
-			schema.Properties[tag.Name] = openapi3.NewSchemaRef("", fieldSchema)
+			if tag.Name == "-" {
+				continue
+			}
+			if tag.Name == "" {
+				tag.Name = field.Name
+			}
+			fieldSchema := swagger.getSchemaRefByType(value.Interface(), true)
 			validateTag, err := tags.Get(constants.VALIDATE)
 			if err == nil {
 				if validateTag.Name == "required" {
 					schema.Required = append(schema.Required, tag.Name)
 				}
-				options := validateTag.Options
-				if len(options) > 0 {
-					schema.Properties[tag.Name] = swagger.getValidateSchemaByOptions(
-						value.Interface(),
-						options,
-					)
-					fieldSchema = schema.Properties[tag.Name].Value
-				}
+				options := append([]string{validateTag.Name}, validateTag.Options...)
+				fieldSchema = swagger.getValidateSchemaByOptions(value.Interface(), options)
+				schema.AllOf = append(schema.AllOf, swagger.getConditionalRequired(type_, tag.Name, options)...)
 			}
-			defaultTag, err := tags.Get(constants.DEFAULT)
-			if err == nil {
-				fieldSchema.Default = defaultTag.Name
-			}
-			exampleTag, err := tags.Get(constants.EXAMPLE)
-			if err == nil {
-				fieldSchema.Example = exampleTag.Name
-			}
-			descriptionTag, err := tags.Get(constants.DESCRIPTION)
-			if err == nil {
-				fieldSchema.Description = descriptionTag.Name
+			if isNullableField(field) {
+				fieldSchema = nullable(fieldSchema)
 			}
+			schema.Properties[tag.Name] = swagger.withFieldTags(fieldSchema, field, tags)
 		}
+		promoteFields(schema, embedded)
 	} else if type_.Kind() == reflect.Slice {
 		schema = openapi3.NewArraySchema()
-		schema.Items = &openapi3.SchemaRef{Value: swagger.getRequestSchemaByModel(reflect.New(type_.Elem()).Elem().Interface())}
-	} else if type_.Kind() != reflect.Map {
+		schema.Items = swagger.getSchemaRefByType(reflect.New(type_.Elem()).Elem().Interface(), true)
+	} else if type_.Kind() == reflect.Map {
+		schema = swagger.getMapSchema(type_, true)
+	} else {
 		schema = swagger.getSchemaByType(model, true)
 	}
 	return schema
 }
 
-func (swagger *Swagger) getRequestBodyByModel(
-	model interface{},
-	contentType string,
-) *openapi3.RequestBodyRef {
+func (swagger *Swagger) getRequestBodyByModel(r *router.Router) *openapi3.RequestBodyRef {
 	body := &openapi3.RequestBodyRef{
 		Value: openapi3.NewRequestBody(),
 	}
-	if model == nil {
+	if r.Model == nil {
 		return body
 	}
-	schema := swagger.getRequestSchemaByModel(model)
-	body.Value.Required = true
+	schema := swagger.getSchemaRefByType(r.Model, true)
+	body.Value.Required = len(schema.Value.Required) > 0
+	contentType := r.RequestContentType
 	if contentType == "" {
 		contentType = fiber.MIMEApplicationJSON
 	}
-	body.Value.Content = openapi3.NewContentWithSchema(schema, []string{contentType})
+	body.Value.Content = openapi3.NewContentWithSchemaRef(swagger.withCodecSchema(schema, r, contentType, reflect.TypeOf(r.Model)), []string{contentType})
 	return body
 }
 
@@ -221,70 +348,101 @@ func (swagger *Swagger) getResponseSchemaByModel(model interface{}) *openapi3.Sc
 		type_ = type_.Elem()
 	}
 	if value_.Kind() == reflect.Ptr {
-		value_ = value_.Elem()
+		if value_.IsNil() {
+			value_ = reflect.New(type_).Elem()
+		} else {
+			value_ = value_.Elem()
+		}
 	}
 	if type_.Kind() == reflect.Struct {
+		var embedded []*openapi3.Schema
 		for i := 0; i < type_.NumField(); i++ {
 			field := type_.Field(i)
-			value := value_.Field(i)
-			fieldSchema := swagger.getSchemaByType(value.Interface(), false)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
 			tags, err := structtag.Parse(string(field.Tag))
 			if err != nil {
-				panic(err)
+				swagger.addError(fmt.Errorf("%s.%s: %w", type_, field.Name, err))
+				continue
 			}
-			_, err = tags.Get(constants.EMBED)
-			if err == nil {
-				embedSchema := swagger.getResponseSchemaByModel(value.Interface())
-				for key, embedProperty := range embedSchema.Properties {
-					schema.Properties[key] = embedProperty
-				}
-				for _, name := range embedSchema.Required {
-					schema.Required = append(schema.Required, name)
-				}
+			if isEmbeddedField(field, tags) {
+				embedded = append(embedded, swagger.getResponseSchemaByModel(reflect.Zero(field.Type).Interface()))
+				continue
 			}
+			if !field.IsExported() {
+				continue
+			}
+			value := value_.Field(i)
 			tag, err := tags.Get(constants.JSON)
 			if err != nil {
-				continue
+				tag = &structtag.Tag{Key: constants.JSON, Name: field.Name}
 			}
-			validateTag, err := tags.Get(constants.VALIDATE)
-			if err == nil && validateTag.Name == "required" {
-				schema.Required = append(schema.Required, tag.Name)
+			if tag.Name == "-" && len(tag.Options) == 0 {
+				continue
 			}
-			descriptionTag, err := tags.Get(constants.DESCRIPTION)
-			if err == nil {
-				fieldSchema.Description = descriptionTag.Name
+			if tag.Name == "" {
+				tag.Name = field.Name
 			}
-			defaultTag, err := tags.Get(constants.DEFAULT)
-			if err == nil {
-				fieldSchema.Default = defaultTag.Name
+			// encoding/json always writes fields without omitempty
+			if !tag.HasOption("omitempty") {
+				schema.Required = append(schema.Required, tag.Name)
 			}
-			exampleTag, err := tags.Get(constants.EXAMPLE)
-			if err == nil {
-				fieldSchema.Example = exampleTag.Name
+			fieldSchema := swagger.getSchemaRefByType(value.Interface(), false)
+			if isNullableField(field) {
+				fieldSchema = nullable(fieldSchema)
 			}
-			schema.Properties[tag.Name] = openapi3.NewSchemaRef("", fieldSchema)
+			schema.Properties[tag.Name] = swagger.withFieldTags(fieldSchema, field, tags)
 		}
+		promoteFields(schema, embedded)
 	} else if type_.Kind() == reflect.Slice {
 		schema = openapi3.NewArraySchema()
-		schema.Items = &openapi3.SchemaRef{Value: swagger.getResponseSchemaByModel(reflect.New(type_.Elem()).Elem().Interface())}
-	} else if type_.Kind() != reflect.Map {
+		schema.Items = swagger.getSchemaRefByType(reflect.New(type_.Elem()).Elem().Interface(), false)
+	} else if type_.Kind() == reflect.Map {
+		schema = swagger.getMapSchema(type_, false)
+	} else {
 		schema = swagger.getSchemaByType(model, false)
 	}
 	return schema
 }
 
-func (swagger *Swagger) getResponses(
-	response router.Response,
-	contentType string,
-) openapi3.Responses {
+// getMapSchema documents the values of a map as additional properties, its keys have
+// to be strings as they become JSON object keys
+func (swagger *Swagger) getMapSchema(type_ reflect.Type, request bool) *openapi3.Schema {
+	schema := openapi3.NewObjectSchema()
+	if type_.Key().Kind() != reflect.String && !type_.Key().Implements(textMarshalerType) {
+		swagger.addError(fmt.Errorf("%s: map keys must be strings, got %s", type_, type_.Key()))
+		return schema
+	}
+	schema.AdditionalProperties.Schema = swagger.getSchemaRefByType(reflect.New(type_.Elem()).Elem().Interface(), request)
+	return schema
+}
+
+// newAnySchema returns a schema accepting any JSON value
+func newAnySchema() *openapi3.Schema {
+	return &openapi3.Schema{Nullable: true}
+}
+
+func (swagger *Swagger) getResponses(r *router.Router) openapi3.Responses {
 	ret := openapi3.NewResponses()
-	for k, v := range response {
-		schema := swagger.getResponseSchemaByModel(v.Model)
+	for k, v := range r.Response {
 		var content openapi3.Content
-		if contentType == "" || contentType == fiber.MIMEApplicationJSON {
-			content = openapi3.NewContentWithJSONSchema(schema)
+		if k == "200" && len(r.Events) > 0 {
+			content = openapi3.NewContentWithSchema(swagger.getEventsSchema(r.Events), []string{router.MIMETextEventStream})
 		} else {
-			content = openapi3.NewContentWithSchema(schema, []string{contentType})
+			schema := openapi3.NewSchemaRef("", openapi3.NewObjectSchema())
+			type_ := reflect.TypeOf(v.Model)
+			if v.Model != nil && r.Envelope != nil && r.Envelope.Template != nil && strings.HasPrefix(k, "2") {
+				schema, type_ = swagger.getEnvelopeRef(r.Envelope.Template, v.Model)
+			} else if v.Model != nil {
+				schema = swagger.getSchemaRefByType(v.Model, false)
+			}
+			schema = swagger.withCodecSchema(schema, r, r.ResponseContentType, type_)
+			if r.ResponseContentType == "" || r.ResponseContentType == fiber.MIMEApplicationJSON {
+				content = openapi3.NewContentWithJSONSchemaRef(schema)
+			} else {
+				content = openapi3.NewContentWithSchemaRef(schema, []string{r.ResponseContentType})
+			}
 		}
 		description := v.Description
 		ret[k] = &openapi3.ResponseRef{
@@ -295,48 +453,62 @@ func (swagger *Swagger) getResponses(
 			},
 		}
 	}
+	// errors of binding a model and of validating the request against the docs
+	if (r.Model != nil || r.RequestValidation) && r.ErrorMode == router.ErrorModeStructured {
+		codes := map[string]string{"400": "Bad Request", "422": "Validation Error"}
+		if r.RequestValidation && len(r.Securities) > 0 {
+			codes["401"] = "Unauthorized"
+		}
+		for code, description := range codes {
+			if _, ok := ret[code]; !ok {
+				schema := swagger.getSchemaRefByType(router.ValidationError{}, false)
+				ret[code] = &openapi3.ResponseRef{
+					Value: openapi3.NewResponse().WithDescription(description).WithJSONSchemaRef(schema),
+				}
+			}
+		}
+	}
 	return ret
 }
 
+// getEventsSchema documents each event of a stream as one of its variants
+func (swagger *Swagger) getEventsSchema(events router.Events) *openapi3.Schema {
+	names := make([]string, 0, len(events))
+	for name := range events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	schema := &openapi3.Schema{}
+	for _, name := range names {
+		event := events[name]
+		eventSchema := openapi3.NewObjectSchema().
+			WithProperty("id", openapi3.NewStringSchema()).
+			WithProperty("event", openapi3.NewStringSchema().WithEnum(name)).
+			WithPropertyRef("data", swagger.getSchemaRefByType(event.Model, false))
+		eventSchema.Title = name
+		eventSchema.Description = event.Description
+		eventSchema.Required = []string{"event", "data"}
+		schema.OneOf = append(schema.OneOf, openapi3.NewSchemaRef("", eventSchema))
+	}
+	return schema
+}
+
 func (swagger *Swagger) getValidateSchemaByOptions(
 	value interface{},
 	options []string,
 ) *openapi3.SchemaRef {
-	schema := openapi3.NewSchemaRef("", swagger.getSchemaByType(value, true))
-	for _, option := range options {
-		if strings.HasPrefix(option, "oneof=") {
-			optionItems := strings.Split(option[6:], " ")
-			enums := make([]interface{}, len(optionItems))
-			for i, optionItem := range optionItems {
-				enums[i] = optionItem
-			}
-			schema.Value.WithEnum(enums...)
-		}
-		if strings.HasPrefix(option, "max=") {
-			value, err := strconv.ParseFloat(option[4:], 64)
-			if err != nil {
-				log.Panicln(err)
-			}
-			schema.Value.WithMax(value)
-		}
-		if strings.HasPrefix(option, "min=") {
-			value, err := strconv.ParseFloat(option[4:], 64)
-			if err != nil {
-				log.Panicln(err)
-			}
-			schema.Value.WithMin(value)
-		}
-		if strings.HasPrefix(option, "len=") {
-			value, err := strconv.ParseInt(option[4:], 10, 64)
-			if err != nil {
-				log.Panicln(err)
-			}
-			schema.Value.WithLength(value)
-		}
-	}
+	schema := inline(swagger.getSchemaRefByType(value, true))
+	swagger.applyConstraints(schema.Value, options)
 	return schema
 }
 
+func (swagger *Swagger) validator() *router.Validator {
+	if swagger.Validator == nil {
+		return router.DefaultValidator
+	}
+	return swagger.Validator
+}
+
 func (swagger *Swagger) getParametersByModel(model interface{}) openapi3.Parameters {
 	parameters := openapi3.NewParameters()
 	if model == nil {
@@ -348,25 +520,34 @@ func (swagger *Swagger) getParametersByModel(model interface{}) openapi3.Paramet
 	}
 	value_ := reflect.ValueOf(model)
 	if value_.Kind() == reflect.Ptr {
-		value_ = value_.Elem()
+		if value_.IsNil() {
+			value_ = reflect.New(type_).Elem()
+		} else {
+			value_ = value_.Elem()
+		}
 	}
 	for i := 0; i < type_.NumField(); i++ {
 		field := type_.Field(i)
-		value := value_.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
 		tags, err := structtag.Parse(string(field.Tag))
 		if err != nil {
-			panic(err)
+			swagger.addError(fmt.Errorf("%s.%s: %w", type_, field.Name, err))
+			continue
 		}
-		_, err = tags.Get(constants.EMBED)
-		if err == nil {
-			embedParameters := swagger.getParametersByModel(value.Interface())
+		if isEmbeddedField(field, tags) && !isParameterField(tags) {
+			embedParameters := swagger.getParametersByModel(reflect.Zero(field.Type).Interface())
 			for _, embedParameter := range embedParameters {
 				parameters = append(parameters, embedParameter)
 			}
+			continue
 		}
-		parameter := &openapi3.Parameter{
-			Schema: openapi3.NewSchemaRef("", swagger.getSchemaByType(value.Interface(), true)),
+		if !field.IsExported() {
+			continue
 		}
+		value := value_.Field(i)
+		parameter := &openapi3.Parameter{}
 		queryTag, err := tags.Get(constants.QUERY)
 		if err == nil {
 			parameter.In = openapi3.ParameterInQuery
@@ -390,6 +571,7 @@ func (swagger *Swagger) getParametersByModel(model interface{}) openapi3.Paramet
 		if parameter.In == "" {
 			continue
 		}
+		parameter.Schema = swagger.getSchemaRefByType(value.Interface(), true)
 		descriptionTag, err := tags.Get(constants.DESCRIPTION)
 		if err == nil {
 			parameter.WithDescription(descriptionTag.Name)
@@ -397,18 +579,24 @@ func (swagger *Swagger) getParametersByModel(model interface{}) openapi3.Paramet
 		validateTag, err := tags.Get(constants.VALIDATE)
 		if err == nil {
 			parameter.WithRequired(validateTag.Name == "required")
-			options := validateTag.Options
+			options := append([]string{validateTag.Name}, validateTag.Options...)
 			if len(options) > 0 {
 				parameter.Schema = swagger.getValidateSchemaByOptions(value.Interface(), options)
 			}
 		}
+		// path parameters are always present
+		if parameter.In == openapi3.ParameterInPath {
+			parameter.Required = true
+		}
 		defaultTag, err := tags.Get(constants.DEFAULT)
 		if err == nil {
-			parameter.Schema.Value.WithDefault(defaultTag.Name)
+			parameter.Schema = inline(parameter.Schema)
+			parameter.Schema.Value.WithDefault(swagger.getTagValue(field, defaultTag))
 		}
 		exampleTag, err := tags.Get(constants.EXAMPLE)
 		if err == nil {
-			parameter.Schema.Value.Example = exampleTag.Name
+			parameter.Schema = inline(parameter.Schema)
+			parameter.Schema.Value.Example = swagger.getTagValue(field, exampleTag)
 		}
 		parameters = append(parameters, &openapi3.ParameterRef{
 			Value: parameter,
@@ -417,6 +605,17 @@ func (swagger *Swagger) getParametersByModel(model interface{}) openapi3.Paramet
 	return parameters
 }
 
+// getPathParameters documents the parameters of a path as strings
+func getPathParameters(path string) openapi3.Parameters {
+	parameters := openapi3.NewParameters()
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		parameters = append(parameters, &openapi3.ParameterRef{
+			Value: openapi3.NewPathParameter(match[1]).WithSchema(openapi3.NewStringSchema()),
+		})
+	}
+	return parameters
+}
+
 // /:id -> /{id}
 func (swagger *Swagger) fixPath(path string) string {
 	reg := regexp.MustCompile("/:(\\w+)")
@@ -431,6 +630,7 @@ func (swagger *Swagger) getPaths() openapi3.Paths {
 			if r.Exclude {
 				continue
 			}
+			swagger.path, swagger.method = path, method
 			model := r.Model
 			operation := &openapi3.Operation{
 				Tags:        r.Tags,
@@ -438,21 +638,25 @@ func (swagger *Swagger) getPaths() openapi3.Paths {
 				Summary:     r.Summary,
 				Description: r.Description,
 				Deprecated:  r.Deprecated,
-				Responses:   swagger.getResponses(r.Response, r.ResponseContentType),
+				Responses:   swagger.getResponses(r),
 				Parameters:  swagger.getParametersByModel(model),
 				Security:    swagger.getSecurityRequirements(r.Securities),
 			}
-			requestBody := swagger.getRequestBodyByModel(model, r.RequestContentType)
+			// handlers without a model read the parameters of the path themselves
+			if model == nil {
+				operation.Parameters = getPathParameters(path)
+			}
+			if model != nil && r.HasBody(method) {
+				operation.RequestBody = swagger.getRequestBodyByModel(r)
+			}
 			if method == http.MethodGet {
 				pathItem.Get = operation
 			} else if method == http.MethodPost {
 				pathItem.Post = operation
-				operation.RequestBody = requestBody
 			} else if method == http.MethodDelete {
 				pathItem.Delete = operation
 			} else if method == http.MethodPut {
 				pathItem.Put = operation
-				operation.RequestBody = requestBody
 			} else if method == http.MethodPatch {
 				pathItem.Patch = operation
 			} else if method == http.MethodHead {
@@ -470,11 +674,12 @@ func (swagger *Swagger) getPaths() openapi3.Paths {
 	return paths
 }
 
-func (swagger *Swagger) BuildOpenAPI() {
+// BuildOpenAPI builds the document from the routers, returning every problem found as Errors
+func (swagger *Swagger) BuildOpenAPI() error {
 	components := openapi3.NewComponents()
 	components.SecuritySchemes = openapi3.SecuritySchemes{}
 	swagger.OpenAPI = &openapi3.T{
-		OpenAPI: "3.0.0",
+		OpenAPI: OpenAPI30,
 		Info: &openapi3.Info{
 			Title:          swagger.Title,
 			Description:    swagger.Description,
@@ -486,11 +691,73 @@ func (swagger *Swagger) BuildOpenAPI() {
 		Servers:    swagger.Servers,
 		Components: &components,
 	}
+	swagger.components = make(map[componentKey]*component)
+	swagger.inlining = make(map[reflect.Type]bool)
+	swagger.envelopes = make(map[reflect.Type]envelope)
+	swagger.errors = nil
+	swagger.mappings = nil
+	swagger.path, swagger.method = "", ""
+	if swagger.OpenAPIVersion != "" && swagger.OpenAPIVersion != OpenAPI30 && swagger.OpenAPIVersion != OpenAPI31 {
+		swagger.addError(fmt.Errorf("unsupported OpenAPI version %q", swagger.OpenAPIVersion))
+	}
 	swagger.OpenAPI.Paths = swagger.getPaths()
+	swagger.path, swagger.method = "", ""
+	components.Schemas = swagger.buildComponents()
+	swagger.buildMappings()
+	swagger.validate()
+	if len(swagger.errors) > 0 {
+		return swagger.errors
+	}
+	return nil
+}
+
+// PathItem returns the path item built for a router's path
+func (swagger *Swagger) PathItem(path string) *openapi3.PathItem {
+	if swagger.OpenAPI == nil {
+		return nil
+	}
+	return swagger.OpenAPI.Paths[swagger.fixPath(path)]
+}
+
+// Operation returns the operation built for a router's path and method
+func (swagger *Swagger) Operation(path string, method string) *openapi3.Operation {
+	pathItem := swagger.PathItem(path)
+	if pathItem == nil {
+		return nil
+	}
+	return pathItem.GetOperation(method)
 }
 
 func (swagger *Swagger) MarshalJSON() ([]byte, error) {
-	return swagger.OpenAPI.MarshalJSON()
+	data, err := swagger.OpenAPI.MarshalJSON()
+	if err != nil || swagger.OpenAPIVersion != OpenAPI31 {
+		return data, err
+	}
+	return marshalOpenAPI31(data)
+}
+
+// MarshalYAML writes the document as YAML with the properties in the order of the JSON one
+func (swagger *Swagger) MarshalYAML() (interface{}, error) {
+	data, err := swagger.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	// JSON is YAML, decoding it into a node keeps the order of the keys
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	return node.Content[0], nil
+}
+
+// blockStyle drops the flow style and quotes of nodes decoded from JSON, the encoder
+// quotes the strings that need it
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
 }
 
 func (swagger *Swagger) WithDocsUrl(url string) *Swagger {
@@ -523,6 +790,11 @@ func (swagger *Swagger) WithOpenAPIUrl(url string) *Swagger {
 	return swagger
 }
 
+func (swagger *Swagger) WithOpenAPIYAMLUrl(url string) *Swagger {
+	OpenAPIYAMLUrl(url)(swagger)
+	return swagger
+}
+
 func (swagger *Swagger) WithTermsOfService(termsOfService string) *Swagger {
 	TermsOfService(termsOfService)(swagger)
 	return swagger
@@ -552,3 +824,18 @@ func (swagger *Swagger) WithRedocOptions(options map[string]interface{}) *Swagge
 	RedocOptions(options)(swagger)
 	return swagger
 }
+
+func (swagger *Swagger) WithSchemaNamer(namer func(type_ reflect.Type) string) *Swagger {
+	SchemaNamer(namer)(swagger)
+	return swagger
+}
+
+func (swagger *Swagger) WithTypeSchema(value interface{}, schema *openapi3.Schema) *Swagger {
+	TypeSchema(value, schema)(swagger)
+	return swagger
+}
+
+func (swagger *Swagger) WithOpenAPIVersion(version string) *Swagger {
+	OpenAPIVersion(version)(swagger)
+	return swagger
+}
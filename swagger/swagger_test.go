@@ -0,0 +1,109 @@
+package swagger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+)
+
+// document builds the document of routers keyed by method and path, like POST /users, and
+// returns it decoded from JSON
+func document(t *testing.T, routers map[string]*router.Router) map[string]interface{} {
+	t.Helper()
+	swagger, err := buildRouters(routers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := swagger.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err = json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// buildRouters builds the document of routers keyed by method and path
+func buildRouters(routers map[string]*router.Router) (*Swagger, error) {
+	swagger := New("test", "test", "1.0.0")
+	swagger.Routers = make(map[string]map[string]*router.Router)
+	for route, r := range routers {
+		method, path, _ := strings.Cut(route, " ")
+		r.Method, r.Path = method, path
+		if swagger.Routers[path] == nil {
+			swagger.Routers[path] = make(map[string]*router.Router)
+		}
+		swagger.Routers[path][method] = r
+	}
+	return swagger, swagger.BuildOpenAPI()
+}
+
+// componentSchema returns the schema of a component of a document built by document
+func componentSchema(t *testing.T, doc map[string]interface{}, name string) map[string]interface{} {
+	t.Helper()
+	schemas, _ := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	schema, ok := schemas[name].(map[string]interface{})
+	if !ok {
+		t.Fatalf("no component %s in %v", name, schemas)
+	}
+	return schema
+}
+
+// property returns the schema of a property of a component of a document built by document
+func property(t *testing.T, doc map[string]interface{}, name string, property string) interface{} {
+	t.Helper()
+	properties, _ := componentSchema(t, doc, name)["properties"].(map[string]interface{})
+	return properties[property]
+}
+
+type mapsModel struct {
+	Labels map[string]string      `json:"labels"`
+	Users  map[string]user        `json:"users"`
+	Meta   map[string]interface{} `json:"meta"`
+	Raw    json.RawMessage        `json:"raw"`
+	Any    interface{}            `json:"any"`
+	IDs    map[textKey]int        `json:"ids"`
+}
+
+// textKey is written as a string key by encoding/json as it implements encoding.TextMarshaler
+type textKey struct{}
+
+func (textKey) MarshalText() ([]byte, error) {
+	return []byte("key"), nil
+}
+
+func TestMapsAndFreeForm(t *testing.T) {
+	doc := document(t, map[string]*router.Router{
+		"POST /maps": router.NewR(func(c *fiber.Ctx, req mapsModel) (mapsModel, error) { return req, nil }),
+	})
+	tests := map[string]string{
+		"labels": `{"type":"object","additionalProperties":{"type":"string"}}`,
+		"users":  `{"type":"object","additionalProperties":{"$ref":"#/components/schemas/user"}}`,
+		"meta":   `{"type":"object","additionalProperties":{"nullable":true}}`,
+		"raw":    `{"nullable":true}`,
+		"any":    `{"nullable":true}`,
+		"ids":    `{"type":"object","additionalProperties":{"type":"integer"}}`,
+	}
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertJSON(t, property(t, doc, "mapsModel", name), want)
+		})
+	}
+}
+
+func TestMapsWithoutStringKeys(t *testing.T) {
+	type model struct {
+		Scores map[int]string `json:"scores"`
+	}
+	_, err := buildRouters(map[string]*router.Router{
+		"POST /scores": router.New(func(c *fiber.Ctx, req model) error { return nil }),
+	})
+	if err == nil || !strings.Contains(err.Error(), "map keys must be strings") {
+		t.Errorf("got %v, want an error about the keys", err)
+	}
+}
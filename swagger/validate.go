@@ -0,0 +1,106 @@
+package swagger
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// pathParam matches the parameters of fiber paths such as :id and :id?
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// validate checks the document against the OpenAPI specification and the parameters of each
+// router against its path, recording every problem found against the router at fault
+func (swagger *Swagger) validate() {
+	ctx := context.Background()
+	operationErrors := swagger.validateOperations(ctx)
+	swagger.validateDocument(ctx, operationErrors)
+}
+
+// validateDocument checks the whole document, which stops at its first problem, so problems
+// of operations already recorded against their routers are checked again without the paths
+// to find the problems of the rest of the document
+func (swagger *Swagger) validateDocument(ctx context.Context, operationErrors []error) {
+	err := swagger.OpenAPI.Validate(ctx)
+	if err == nil {
+		return
+	}
+	for _, operationError := range operationErrors {
+		if strings.Contains(err.Error(), operationError.Error()) {
+			doc := *swagger.OpenAPI
+			doc.Paths = openapi3.Paths{}
+			err = doc.Validate(ctx)
+			break
+		}
+	}
+	if err != nil {
+		swagger.addError(err)
+	}
+}
+
+// validateOperations checks each operation and its cross references, returning the problems
+// found by the OpenAPI specification
+func (swagger *Swagger) validateOperations(ctx context.Context) []error {
+	var operationErrors []error
+	paths := make([]string, 0, len(swagger.Routers))
+	for path := range swagger.Routers {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	operationIDs := make(map[string]string)
+	for _, path := range paths {
+		methods := make([]string, 0, len(swagger.Routers[path]))
+		for method := range swagger.Routers[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			operation := swagger.Operation(path, method)
+			if swagger.Routers[path][method].Exclude || operation == nil {
+				continue
+			}
+			swagger.path, swagger.method = path, method
+			if err := operation.Validate(ctx); err != nil {
+				operationErrors = append(operationErrors, err)
+				swagger.addError(err)
+			}
+			swagger.validatePathParameters(path, operation)
+			if id := operation.OperationID; id != "" {
+				if other, ok := operationIDs[id]; ok {
+					swagger.addError(fmt.Errorf("operationId %q is already used by %s", id, other))
+				} else {
+					operationIDs[id] = method + " " + path
+				}
+			}
+		}
+	}
+	swagger.path, swagger.method = "", ""
+	return operationErrors
+}
+
+// validatePathParameters checks that the parameters of a path and the uri fields of its model match
+func (swagger *Swagger) validatePathParameters(path string, operation *openapi3.Operation) {
+	declared := make(map[string]bool)
+	for _, parameter := range operation.Parameters {
+		if parameter.Value != nil && parameter.Value.In == openapi3.ParameterInPath {
+			declared[parameter.Value.Name] = true
+		}
+	}
+	inPath := make(map[string]bool)
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		name := match[1]
+		inPath[name] = true
+		if !declared[name] {
+			swagger.addError(fmt.Errorf("path parameter %s has no field tagged uri:%q", name, name))
+		}
+	}
+	for _, parameter := range operation.Parameters {
+		if parameter.Value != nil && parameter.Value.In == openapi3.ParameterInPath && !inPath[parameter.Value.Name] {
+			swagger.addError(fmt.Errorf("field tagged uri:%q is not a parameter of the path", parameter.Value.Name))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package swagger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+)
+
+type pathParamsReq struct {
+	ID int `uri:"id"`
+}
+
+func TestValidatePathParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		router *router.Router
+		err    string
+	}{
+		{"model", "/users/:id", router.New(func(c *fiber.Ctx, req pathParamsReq) error { return nil }), ""},
+		{"without model", "/users/:id/posts/:post",
+			router.NewX(func(c *fiber.Ctx) error { return nil }), ""},
+		{"missing field", "/users/:id/posts/:post",
+			router.New(func(c *fiber.Ctx, req pathParamsReq) error { return nil }),
+			`path parameter post has no field tagged uri:"post"`},
+		{"missing parameter", "/users",
+			router.New(func(c *fiber.Ctx, req pathParamsReq) error { return nil }),
+			`field tagged uri:"id" is not a parameter of the path`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			swagger := New("test", "test", "1.0.0")
+			test.router.Path, test.router.Method = test.path, fiber.MethodGet
+			swagger.Routers = map[string]map[string]*router.Router{test.path: {fiber.MethodGet: test.router}}
+			err := swagger.BuildOpenAPI()
+			if test.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Fatalf("got %v, want %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestPathParametersWithoutModel(t *testing.T) {
+	swagger := New("test", "test", "1.0.0")
+	r := router.NewX(func(c *fiber.Ctx) error { return nil })
+	r.Path, r.Method = "/users/:id", fiber.MethodGet
+	swagger.Routers = map[string]map[string]*router.Router{r.Path: {r.Method: r}}
+	if err := swagger.BuildOpenAPI(); err != nil {
+		t.Fatal(err)
+	}
+	parameter := swagger.Operation(r.Path, r.Method).Parameters.GetByInAndName(openapi3.ParameterInPath, "id")
+	if parameter == nil || !parameter.Required || parameter.Schema.Value.Type != openapi3.TypeString {
+		t.Errorf("got %+v, want a required string parameter", parameter)
+	}
+}
+
+type invalidParam struct{}
+
+type invalidParamReq struct {
+	ID   int          `uri:"id"`
+	Kind invalidParam `query:"kind"`
+}
+
+// problems of the document outside the operations are found along with those of the operations
+func TestValidateDocument(t *testing.T) {
+	valid := router.New(func(c *fiber.Ctx, req pathParamsReq) error { return nil })
+	invalid := router.New(func(c *fiber.Ctx, req invalidParamReq) error { return nil })
+	invalidSchema := TypeSchema(invalidParam{}, &openapi3.Schema{Type: "bogus"})
+	tests := []struct {
+		name    string
+		version string
+		options []Option
+		router  *router.Router
+		errs    []string
+	}{
+		{"valid", "1.0.0", nil, valid, nil},
+		{"info", "", nil, valid, []string{"invalid info: value of version must be a non-empty string"}},
+		{"servers", "1.0.0", []Option{Servers(openapi3.Servers{{}})}, valid,
+			[]string{"invalid servers: value of url must be a non-empty string"}},
+		{"operation", "1.0.0", []Option{invalidSchema}, invalid, []string{`GET /users/:id: parameter "kind" schema is invalid`}},
+		{"operation and servers", "1.0.0", []Option{invalidSchema, Servers(openapi3.Servers{{}})}, invalid,
+			[]string{`GET /users/:id: parameter "kind" schema is invalid`, "invalid servers: value of url must be a non-empty string"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			swagger := New("test", "test", test.version, test.options...)
+			test.router.Path, test.router.Method = "/users/:id", fiber.MethodGet
+			swagger.Routers = map[string]map[string]*router.Router{test.router.Path: {fiber.MethodGet: test.router}}
+			var errs Errors
+			if err := swagger.BuildOpenAPI(); err != nil {
+				errs = err.(Errors)
+			}
+			if len(errs) != len(test.errs) {
+				t.Fatalf("got %v, want %d errors", errs, len(test.errs))
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), test.errs[i]) {
+					t.Errorf("got %v, want %s", err, test.errs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package swagger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/fatih/structtag"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// getTagValue parses the default or example tag of a field as a value of its type, slices,
+// maps and structs are written as JSON literals
+func (swagger *Swagger) getTagValue(field reflect.StructField, tag *structtag.Tag) interface{} {
+	// the tag parser splits values on commas
+	value := tag.Value()
+	parsed, err := parseTagValue(field.Type, value)
+	if err != nil {
+		swagger.addError(fmt.Errorf("%s tag %q of field %s is not a valid %s: %w", tag.Key, value, field.Name, field.Type, err))
+		return value
+	}
+	return parsed
+}
+
+func parseTagValue(type_ reflect.Type, value string) (interface{}, error) {
+	for type_.Kind() == reflect.Ptr {
+		type_ = type_.Elem()
+	}
+	if wrapped, ok := nullableValue(reflect.New(type_).Elem().Interface()); ok {
+		if wrapped == nil {
+			return parseJSONValue(nil, value)
+		}
+		return parseTagValue(reflect.TypeOf(wrapped), value)
+	}
+	// types such as time.Time and uuid.UUID are written as strings
+	if reflect.PtrTo(type_).Implements(textUnmarshalerType) {
+		return value, reflect.New(type_).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	switch type_.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, type_.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(value, 10, type_.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, type_.Bits())
+	case reflect.Slice:
+		if type_.Elem().Kind() == reflect.Uint8 {
+			return value, nil
+		}
+	case reflect.Interface:
+		return parseJSONValue(nil, value)
+	}
+	return parseJSONValue(type_, value)
+}
+
+// parseJSONValue decodes a JSON literal, checking it against type_ if given
+func parseJSONValue(type_ reflect.Type, value string) (interface{}, error) {
+	if type_ != nil {
+		if err := json.Unmarshal([]byte(value), reflect.New(type_).Interface()); err != nil {
+			return nil, err
+		}
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		// free-form values may be plain strings
+		if type_ == nil {
+			return value, nil
+		}
+		return nil, err
+	}
+	return parsed, nil
+}
@@ -0,0 +1,96 @@
+package swagger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+	"github.com/long2ice/fibers/types"
+)
+
+func TestParseTagValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		tag   string
+		want  interface{}
+	}{
+		{"string", "", "a,b", "a,b"},
+		{"int", 0, "-3", int64(-3)},
+		{"uint", uint8(0), "255", uint64(255)},
+		{"float", 0.0, "1.5", 1.5},
+		{"bool", false, "true", true},
+		{"pointer", new(int), "3", int64(3)},
+		{"time", time.Time{}, "2006-01-02T15:04:05Z", "2006-01-02T15:04:05Z"},
+		{"bytes", []byte{}, "raw", "raw"},
+		{"slice", []int{}, "[1,2]", []interface{}{1.0, 2.0}},
+		{"map", map[string]string{}, `{"a":"b"}`, map[string]interface{}{"a": "b"}},
+		{"struct", user{}, `{"name":"a"}`, map[string]interface{}{"name": "a"}},
+		{"optional", types.Optional[int]{}, "3", int64(3)},
+		{"any JSON", (*interface{})(nil), "[1]", []interface{}{1.0}},
+		{"any string", (*interface{})(nil), "a", "a"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTagValue(reflect.TypeOf(test.value), test.tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTagValueErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		tag   string
+	}{
+		{"int", 0, "a"},
+		{"int overflow", int8(0), "300"},
+		{"negative uint", uint(0), "-1"},
+		{"bool", false, "yes"},
+		{"time", time.Time{}, "today"},
+		{"slice", []int{}, `["a"]`},
+		{"struct", user{}, `{"name":1}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := parseTagValue(reflect.TypeOf(test.value), test.tag); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+type valuesReq struct {
+	Page  int      `query:"page" default:"1" example:"2"`
+	Tags  []string `json:"tags" example:"[\"a\",\"b\"]"`
+	Ratio float64  `json:"ratio" default:"0.5"`
+}
+
+func TestTagValues(t *testing.T) {
+	doc := document(t, map[string]*router.Router{
+		"POST /values": router.New(func(c *fiber.Ctx, req valuesReq) error { return nil }),
+	})
+	assertJSON(t, property(t, doc, "valuesReq", "tags"), `{"type":"array","items":{"type":"string"},"example":["a","b"]}`)
+	assertJSON(t, property(t, doc, "valuesReq", "ratio"), `{"type":"number","default":0.5}`)
+	parameters := doc["paths"].(map[string]interface{})["/values"].(map[string]interface{})["post"].(map[string]interface{})["parameters"]
+	assertJSON(t, parameters.([]interface{})[0].(map[string]interface{})["schema"], `{"type":"integer","default":1,"example":2}`)
+
+	type invalid struct {
+		Page int `query:"page" default:"first"`
+	}
+	_, err := buildRouters(map[string]*router.Router{
+		"GET /invalid": router.New(func(c *fiber.Ctx, req invalid) error { return nil }),
+	})
+	if err == nil || !strings.Contains(err.Error(), `default tag "first" of field Page`) {
+		t.Errorf("got %v, want an error about the default tag", err)
+	}
+}
@@ -0,0 +1,30 @@
+package types
+
+import "reflect"
+
+// Enum is implemented by named types whose values are limited to a set, such as
+//
+//	type Status string
+//
+//	func (Status) Values() []any { return []any{StatusActive, StatusInactive} }
+//
+// they are documented with enum and bound values outside the set are rejected.
+type Enum interface {
+	Values() []interface{}
+}
+
+var enumType = reflect.TypeOf((*Enum)(nil)).Elem()
+
+// IsEnum reports whether type_ implements Enum, with a value or a pointer receiver
+func IsEnum(type_ reflect.Type) bool {
+	return reflect.PtrTo(type_).Implements(enumType)
+}
+
+// EnumValues returns the values of type_ if it implements Enum, with a value or a pointer
+// receiver
+func EnumValues(type_ reflect.Type) ([]interface{}, bool) {
+	if !IsEnum(type_) {
+		return nil, false
+	}
+	return reflect.New(type_).Interface().(Enum).Values(), true
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Polymorphic describes the variants of a OneOf, it is implemented by OneOf and by the type
+// declaring its variants, for instance
+//
+//	type EventVariants struct{}
+//
+//	func (EventVariants) Variants() (string, []types.Variant) {
+//		return "type", []types.Variant{
+//			{Value: "created", Type: Created{}},
+//			{Value: "deleted", Type: Deleted{}},
+//		}
+//	}
+type Polymorphic interface {
+	// Variants returns the discriminator property and the variants in order
+	Variants() (string, []Variant)
+}
+
+// Variant is a type a OneOf decodes into when its discriminator property has Value
+type Variant struct {
+	// Value of the discriminator property, such as a string or a number
+	Value interface{}
+	// Type is a value of the variant type
+	Type interface{}
+}
+
+// Key is the discriminator value as written in the mapping of the docs, the value itself for
+// strings and its JSON literal otherwise
+func (v Variant) Key() string {
+	if s, ok := v.Value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v.Value)
+	return string(data)
+}
+
+// OneOf holds one of the implementations of I declared by V, chosen by the value of the
+// discriminator property
+type OneOf[I any, V Polymorphic] struct {
+	Value I
+}
+
+func (o OneOf[I, V]) Variants() (string, []Variant) {
+	var variants V
+	return variants.Variants()
+}
+
+// MarshalJSON writes the value, setting the discriminator property to the value of the first
+// variant of its type unless it already holds a value declared for that type
+func (o OneOf[I, V]) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(o.Value)
+	if err != nil {
+		return nil, err
+	}
+	property, variants := o.Variants()
+	var object map[string]json.RawMessage
+	if err = json.Unmarshal(data, &object); err != nil {
+		return data, nil
+	}
+	var own []Variant
+	for _, variant := range variants {
+		if reflect.TypeOf(variant.Type) == reflect.TypeOf(o.Value) {
+			own = append(own, variant)
+		}
+	}
+	if len(own) == 0 {
+		return data, nil
+	}
+	if _, err = findVariant(own, object[property]); err == nil {
+		return data, nil
+	}
+	if object[property], err = json.Marshal(own[0].Value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(object)
+}
+
+// UnmarshalJSON decodes data into the variant named by its discriminator property
+func (o *OneOf[I, V]) UnmarshalJSON(data []byte) error {
+	property, variants := o.Variants()
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	variant, err := findVariant(variants, object[property])
+	if err != nil {
+		return fmt.Errorf("%s: %w", property, err)
+	}
+	type_ := reflect.TypeOf(variant.Type)
+	decoded := reflect.New(type_)
+	if type_.Kind() == reflect.Ptr {
+		decoded.Elem().Set(reflect.New(type_.Elem()))
+		err = json.Unmarshal(data, decoded.Elem().Interface())
+	} else {
+		err = json.Unmarshal(data, decoded.Interface())
+	}
+	if err != nil {
+		return err
+	}
+	value, ok := decoded.Elem().Interface().(I)
+	if !ok {
+		return fmt.Errorf("variant %s doesn't implement %s", type_, reflect.TypeOf((*I)(nil)).Elem())
+	}
+	o.Value = value
+	return nil
+}
+
+// findVariant returns the variant whose value is the raw discriminator value, compared as
+// JSON so that numbers and booleans match too
+func findVariant(variants []Variant, raw json.RawMessage) (Variant, error) {
+	var value interface{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return Variant{}, err
+		}
+	}
+	data, _ := json.Marshal(value)
+	for _, variant := range variants {
+		if other, err := json.Marshal(variant.Value); err == nil && bytes.Equal(data, other) {
+			return variant, nil
+		}
+	}
+	return Variant{}, fmt.Errorf("unknown value %s", data)
+}
@@ -0,0 +1,126 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type shape interface{ area() float64 }
+
+type circle struct {
+	Kind   string  `json:"kind"`
+	Radius float64 `json:"radius"`
+}
+
+type square struct {
+	Kind string  `json:"kind"`
+	Side float64 `json:"side"`
+}
+
+func (circle) area() float64  { return 0 }
+func (*square) area() float64 { return 0 }
+
+type shapeVariants struct{}
+
+// circles are declared under two values, the first one is written
+func (shapeVariants) Variants() (string, []Variant) {
+	return "kind", []Variant{
+		{Value: "circle", Type: circle{}},
+		{Value: "round", Type: circle{}},
+		{Value: "square", Type: &square{}},
+	}
+}
+
+type version interface{}
+
+type v1 struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+}
+
+type v2 struct {
+	Version int      `json:"version"`
+	Names   []string `json:"names"`
+}
+
+type versionVariants struct{}
+
+func (versionVariants) Variants() (string, []Variant) {
+	return "version", []Variant{{Value: 1, Type: v1{}}, {Value: 2, Type: v2{}}}
+}
+
+func TestOneOfUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want interface{}
+	}{
+		{"value variant", `{"kind":"circle","radius":2}`, circle{Kind: "circle", Radius: 2}},
+		{"second value of a variant", `{"kind":"round","radius":1}`, circle{Kind: "round", Radius: 1}},
+		{"pointer variant", `{"kind":"square","side":3}`, &square{Kind: "square", Side: 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var o OneOf[shape, shapeVariants]
+			if err := json.Unmarshal([]byte(test.data), &o); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(o.Value, test.want) {
+				t.Errorf("got %#v, want %#v", o.Value, test.want)
+			}
+		})
+	}
+
+	var o OneOf[version, versionVariants]
+	if err := json.Unmarshal([]byte(`{"version":2,"names":["a"]}`), &o); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o.Value, v2{Version: 2, Names: []string{"a"}}) {
+		t.Errorf("got %#v for a numeric discriminator", o.Value)
+	}
+}
+
+func TestOneOfUnmarshalErrors(t *testing.T) {
+	for _, data := range []string{`{"kind":"triangle"}`, `{"radius":1}`, `{"kind":1}`, `[]`} {
+		var o OneOf[shape, shapeVariants]
+		if err := json.Unmarshal([]byte(data), &o); err == nil {
+			t.Errorf("%s: no error", data)
+		}
+	}
+	var o OneOf[version, versionVariants]
+	if err := json.Unmarshal([]byte(`{"version":"1"}`), &o); err == nil || !strings.Contains(err.Error(), "unknown value") {
+		t.Errorf("got %v for a string instead of a number", err)
+	}
+}
+
+func TestOneOfMarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		value OneOf[shape, shapeVariants]
+		want  string
+	}{
+		{"discriminator set", OneOf[shape, shapeVariants]{Value: circle{Kind: "round", Radius: 1}}, `{"kind":"round","radius":1}`},
+		{"first value of the type", OneOf[shape, shapeVariants]{Value: circle{Radius: 1}}, `{"kind":"circle","radius":1}`},
+		{"pointer", OneOf[shape, shapeVariants]{Value: &square{Side: 2}}, `{"kind":"square","side":2}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the value written for a type declared twice doesn't depend on map order
+			for i := 0; i < 20; i++ {
+				data, err := json.Marshal(test.value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != test.want {
+					t.Fatalf("got %s, want %s", data, test.want)
+				}
+			}
+		})
+	}
+	data, err := json.Marshal(OneOf[version, versionVariants]{Value: v1{Name: "a"}})
+	if err != nil || string(data) != `{"name":"a","version":1}` {
+		t.Errorf("got %s %v for a numeric discriminator", data, err)
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+import "encoding/json"
+
+// Nullable is implemented by wrappers that are documented as their value type accepting null
+type Nullable interface {
+	// NullableValue returns a value of the wrapped type
+	NullableValue() interface{}
+}
+
+// Optional is a value that may be null in JSON, unlike a pointer the zero value is null
+type Optional[T any] struct {
+	Value T
+	Valid bool
+}
+
+// Some returns an Optional holding value
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Valid: true}
+}
+
+// Get returns the value and whether it is set
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Valid
+}
+
+func (o Optional[T]) NullableValue() interface{} {
+	return o.Value
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*o = Optional[T]{}
+		return nil
+	}
+	if err := json.Unmarshal(data, &o.Value); err != nil {
+		return err
+	}
+	o.Valid = true
+	return nil
+}
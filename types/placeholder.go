@@ -0,0 +1,13 @@
+package types
+
+// Placeholder stands for the response model in envelope templates, such as
+//
+//	type Envelope[T any] struct {
+//		Data T    `json:"data"`
+//		Meta Meta `json:"meta"`
+//	}
+//
+//	app.Envelope(Envelope[types.Placeholder]{}, wrap)
+//
+// the documented responses of each router replace it with their own model.
+type Placeholder struct{}
@@ -0,0 +1,267 @@
+package fibers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/constants"
+	"github.com/long2ice/fibers/router"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// ResponseValidation decides what happens when a response doesn't match the docs
+type ResponseValidation int
+
+const (
+	// ResponseValidationOff doesn't check responses
+	ResponseValidationOff ResponseValidation = iota
+	// ResponseValidationLog logs mismatched responses as warnings
+	ResponseValidationLog
+	// ResponseValidationFail replaces mismatched responses with a 500 error
+	ResponseValidationFail
+)
+
+// ValidateResponses checks every response against the schema declared for its
+// status code, intended for tests and staging as it decodes every body.
+func (g *App) ValidateResponses(mode ResponseValidation) {
+	g.responseValidation = mode
+}
+
+func (g *App) responseValidator(r *router.Router) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			if err = c.App().Config().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+		operation := g.Swagger.Operation(r.Path, r.Method)
+		if operation == nil || c.Response().IsBodyStream() {
+			return nil
+		}
+		err := validateResponse(operation, r, c.Response().StatusCode(), c.GetRespHeader(fiber.HeaderContentType), c.Response().Body())
+		if err == nil {
+			return nil
+		}
+		if g.responseValidation == ResponseValidationFail {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		log.Warnf("%s %s: %v", r.Method, g.fullPath(r.Path), err)
+		return nil
+	}
+}
+
+func validateResponse(operation *openapi3.Operation, r *router.Router, status int, contentType string, body []byte) error {
+	response := operation.Responses.Get(status)
+	if response == nil {
+		if _, ok := r.Response["default"]; ok {
+			response = operation.Responses.Default()
+		}
+	}
+	if response == nil || response.Value == nil {
+		return fmt.Errorf("response status %d is not declared", status)
+	}
+	if len(response.Value.Content) == 0 {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid response content type %q", contentType)
+	}
+	media := response.Value.Content.Get(mediaType)
+	if media == nil {
+		return fmt.Errorf("response content type %s of status %d is not declared", mediaType, status)
+	}
+	if media.Schema == nil || !(mediaType == fiber.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+	var value interface{}
+	if err = json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("response body of status %d is not valid JSON: %w", status, err)
+	}
+	if err = media.Schema.Value.VisitJSON(value, openapi3.MultiErrors(), openapi3.VisitAsResponse()); err != nil {
+		return fmt.Errorf(
+			"response body of status %d doesn't match the schema: %s",
+			status,
+			strings.Join(schemaErrorMessages(err), "; "),
+		)
+	}
+	return nil
+}
+
+// schemaErrorMessages flattens errors of VisitJSON into one line per failing value
+func schemaErrorMessages(err error) []string {
+	var messages []string
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, item := range e {
+			messages = append(messages, schemaErrorMessages(item)...)
+		}
+	case *openapi3.SchemaError:
+		messages = append(messages, fmt.Sprintf("/%s: %s", strings.Join(e.JSONPointer(), "/"), e.Reason))
+	default:
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+// ValidateRequests checks every request against the generated docs before it is
+// bound, covering parameters, body, content type and presence of credentials,
+// also for routers without a model.
+func (g *App) ValidateRequests() {
+	g.requestValidation = true
+}
+
+func (g *App) requestValidator(r *router.Router) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		operation := g.Swagger.Operation(r.Path, r.Method)
+		if operation == nil {
+			return c.Next()
+		}
+		req := new(http.Request)
+		if err := fasthttpadaptor.ConvertRequest(c.Context(), req, true); err != nil {
+			return err
+		}
+		pathParams := make(map[string]string)
+		for _, param := range c.Route().Params {
+			pathParams[param] = c.Params(param)
+		}
+		contentType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+		input := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route: &routers.Route{
+				Spec:      g.Swagger.OpenAPI,
+				Path:      r.Path,
+				PathItem:  g.Swagger.PathItem(r.Path),
+				Method:    r.Method,
+				Operation: operation,
+			},
+			Options: &openapi3filter.Options{
+				ExcludeRequestBody:  len(c.Body()) > 0 && openapi3filter.RegisteredBodyDecoder(contentType) == nil,
+				MultiError:          true,
+				SkipSettingDefaults: true,
+				AuthenticationFunc:  authenticate,
+			},
+		}
+		err := openapi3filter.ValidateRequest(context.Background(), input)
+		if err == nil {
+			return c.Next()
+		}
+		validationError := requestValidationError(err)
+		if r.ErrorMode == router.ErrorModeStructured {
+			return c.Status(validationError.Code).JSON(validationError)
+		}
+		return fiber.NewError(validationError.Code, validationError.Error())
+	}
+}
+
+// authenticate only checks that credentials of the security scheme are present,
+// verifying them is left to the security handlers of the router.
+func authenticate(_ context.Context, input *openapi3filter.AuthenticationInput) error {
+	scheme := input.SecurityScheme
+	req := input.RequestValidationInput.Request
+	var credentials string
+	if scheme.In != "" && scheme.Name != "" {
+		switch scheme.In {
+		case openapi3.ParameterInHeader:
+			credentials = req.Header.Get(scheme.Name)
+		case openapi3.ParameterInQuery:
+			credentials = req.URL.Query().Get(scheme.Name)
+		case openapi3.ParameterInCookie:
+			if cookie, err := req.Cookie(scheme.Name); err == nil {
+				credentials = cookie.Value
+			}
+		}
+	} else {
+		credentials = req.Header.Get(fiber.HeaderAuthorization)
+		if scheme.Type == "http" && scheme.Scheme != "" &&
+			!strings.HasPrefix(strings.ToLower(credentials), strings.ToLower(scheme.Scheme)+" ") {
+			credentials = ""
+		}
+	}
+	if credentials == "" {
+		return fmt.Errorf("missing credentials of %s", input.SecuritySchemeName)
+	}
+	return nil
+}
+
+// requestValidationError converts errors of openapi3filter.ValidateRequest into a
+// ValidationError, 401 for missing credentials, 422 for values not matching their
+// schema and 400 otherwise.
+func requestValidationError(err error) *router.ValidationError {
+	ret := &router.ValidationError{Code: fiber.StatusBadRequest}
+	var errs []error
+	if multiError, ok := err.(openapi3.MultiError); ok {
+		errs = multiError
+	} else {
+		errs = []error{err}
+	}
+	for _, err = range errs {
+		var securityError *openapi3filter.SecurityRequirementsError
+		if errors.As(err, &securityError) {
+			ret.Code = fiber.StatusUnauthorized
+			ret.Errors = append(ret.Errors, router.FieldError{
+				Location: constants.HEADER,
+				Rule:     "security",
+				Message:  "missing credentials",
+			})
+			continue
+		}
+		var requestError *openapi3filter.RequestError
+		if !errors.As(err, &requestError) {
+			ret.Errors = append(ret.Errors, router.FieldError{
+				Location: router.LocationBody,
+				Rule:     "parse",
+				Message:  err.Error(),
+			})
+			continue
+		}
+		location, field := router.LocationBody, ""
+		if parameter := requestError.Parameter; parameter != nil {
+			location, field = parameter.In, parameter.Name
+			if location == openapi3.ParameterInPath {
+				location = constants.URI
+			}
+		}
+		causes := []error{requestError.Err}
+		if multiError, ok := requestError.Err.(openapi3.MultiError); ok {
+			causes = multiError
+		}
+		for _, cause := range causes {
+			item := router.FieldError{Location: location, Field: field}
+			var schemaError *openapi3.SchemaError
+			switch {
+			case errors.As(cause, &schemaError):
+				if pointer := schemaError.JSONPointer(); len(pointer) > 0 {
+					item.Field = strings.TrimPrefix(strings.Join(append([]string{field}, pointer...), "."), ".")
+				}
+				item.Rule = schemaError.SchemaField
+				item.Message = schemaError.Reason
+			case errors.Is(cause, openapi3filter.ErrInvalidRequired), errors.Is(cause, openapi3filter.ErrInvalidEmptyValue):
+				item.Rule = "required"
+				item.Message = cause.Error()
+			case cause == nil:
+				item.Rule = "content-type"
+				item.Message = requestError.Reason
+			default:
+				item.Rule = "parse"
+				item.Message = cause.Error()
+			}
+			if item.Rule != "parse" && item.Rule != "content-type" && ret.Code != fiber.StatusUnauthorized {
+				ret.Code = fiber.StatusUnprocessableEntity
+			}
+			ret.Errors = append(ret.Errors, item)
+		}
+	}
+	return ret
+}
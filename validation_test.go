@@ -0,0 +1,167 @@
+package fibers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/long2ice/fibers/router"
+	"github.com/long2ice/fibers/security"
+	"github.com/long2ice/fibers/swagger"
+	log "github.com/sirupsen/logrus"
+)
+
+// responseApp returns an app whose routes write a body matching their docs, a body that
+// doesn't and a status that isn't declared
+func responseApp(t *testing.T, mode ResponseValidation) *App {
+	t.Helper()
+	app := New(swagger.New("test", "test", "1.0.0"), fiber.Config{})
+	app.ValidateResponses(mode)
+	user := router.Responses(router.Response{"200": router.ResponseItem{Model: testUser{}, Description: "user"}})
+	app.Get("/match", router.NewX(func(c *fiber.Ctx) error {
+		return c.JSON(testUser{Name: "a"})
+	}, user))
+	app.Get("/mismatch", router.NewX(func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"name": 1})
+	}, user))
+	app.Get("/undeclared", router.NewX(func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusCreated).JSON(testUser{Name: "a"})
+	}, user))
+	if err := app.Init(); err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestValidateResponses(t *testing.T) {
+	tests := []struct {
+		mode    ResponseValidation
+		path    string
+		code    int
+		warning string
+	}{
+		{ResponseValidationFail, "/match", fiber.StatusOK, ""},
+		{ResponseValidationFail, "/mismatch", fiber.StatusInternalServerError, ""},
+		{ResponseValidationFail, "/undeclared", fiber.StatusInternalServerError, ""},
+		{ResponseValidationLog, "/match", fiber.StatusOK, ""},
+		{ResponseValidationLog, "/mismatch", fiber.StatusOK, "doesn't match the schema: /name"},
+		{ResponseValidationLog, "/undeclared", fiber.StatusCreated, "response status 201 is not declared"},
+		{ResponseValidationOff, "/mismatch", fiber.StatusOK, ""},
+		{ResponseValidationOff, "/undeclared", fiber.StatusCreated, ""},
+	}
+	var logs bytes.Buffer
+	defer log.SetOutput(log.StandardLogger().Out)
+	log.SetOutput(&logs)
+	apps := make(map[ResponseValidation]*App)
+	for _, test := range tests {
+		if apps[test.mode] == nil {
+			apps[test.mode] = responseApp(t, test.mode)
+		}
+		logs.Reset()
+		resp, err := apps[test.mode].Test(httptest.NewRequest(fiber.MethodGet, test.path, nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != test.code {
+			t.Errorf("mode %d %s: got %d %s, want %d", test.mode, test.path, resp.StatusCode, body, test.code)
+		}
+		if test.warning == "" && logs.Len() > 0 || !strings.Contains(logs.String(), test.warning) {
+			t.Errorf("mode %d %s: logged %q, want %q", test.mode, test.path, logs.String(), test.warning)
+		}
+	}
+}
+
+type validatedReq struct {
+	ID    int    `uri:"id"`
+	Page  int    `query:"page" validate:"max=10"`
+	Token string `header:"X-Token" validate:"required"`
+	Name  string `json:"name" validate:"required,min=2"`
+}
+
+func TestValidateRequests(t *testing.T) {
+	app := New(swagger.New("test", "test", "1.0.0"), fiber.Config{})
+	app.ValidateRequests()
+	app.ErrorMode(router.ErrorModeStructured)
+	app.Post("/users/:id", router.NewR(func(c *fiber.Ctx, req validatedReq) (testUser, error) {
+		return testUser{Name: req.Name}, nil
+	}))
+	app.Get("/items/:id", router.NewX(func(c *fiber.Ctx) error {
+		return c.SendString(c.Params("id"))
+	}, router.Security(&security.Bearer{})))
+	if err := app.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		headers map[string]string
+		body    string
+		code    int
+		want    router.FieldError
+	}{
+		{"valid", fiber.MethodPost, "/users/1?page=1", map[string]string{"X-Token": "t"}, `{"name":"ab"}`,
+			fiber.StatusOK, router.FieldError{}},
+		{"query type", fiber.MethodPost, "/users/1?page=a", map[string]string{"X-Token": "t"}, `{"name":"ab"}`,
+			fiber.StatusBadRequest, router.FieldError{Location: "query", Field: "page", Rule: "parse"}},
+		{"query rule", fiber.MethodPost, "/users/1?page=20", map[string]string{"X-Token": "t"}, `{"name":"ab"}`,
+			fiber.StatusUnprocessableEntity, router.FieldError{Location: "query", Field: "page", Rule: "maximum"}},
+		{"missing header", fiber.MethodPost, "/users/1", nil, `{"name":"ab"}`,
+			fiber.StatusUnprocessableEntity, router.FieldError{Location: "header", Field: "X-Token", Rule: "required"}},
+		{"content type", fiber.MethodPost, "/users/1", map[string]string{"X-Token": "t", fiber.HeaderContentType: "text/plain"},
+			`name`, fiber.StatusBadRequest, router.FieldError{Location: "body", Rule: "content-type"}},
+		{"body schema", fiber.MethodPost, "/users/1", map[string]string{"X-Token": "t"}, `{"name":"a"}`,
+			fiber.StatusUnprocessableEntity, router.FieldError{Location: "body", Field: "name", Rule: "minLength"}},
+		{"missing security of NewX", fiber.MethodGet, "/items/1", nil, "",
+			fiber.StatusUnauthorized, router.FieldError{Location: "header", Rule: "security"}},
+		{"NewX", fiber.MethodGet, "/items/1", map[string]string{fiber.HeaderAuthorization: "Bearer t"}, "",
+			fiber.StatusOK, router.FieldError{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			if test.body != "" {
+				req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			}
+			for key, value := range test.headers {
+				req.Header.Set(key, value)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			data, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != test.code {
+				t.Fatalf("got %d %s, want %d", resp.StatusCode, data, test.code)
+			}
+			if test.code == fiber.StatusOK {
+				return
+			}
+			var body router.ValidationError
+			if err = json.Unmarshal(data, &body); err != nil || len(body.Errors) != 1 {
+				t.Fatalf("got %s, want one error", data)
+			}
+			got := body.Errors[0]
+			got.Message = ""
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", body.Errors[0], test.want)
+			}
+		})
+	}
+
+	// the errors are documented for routers without a model too
+	for _, code := range []int{fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusUnprocessableEntity} {
+		response := app.Swagger.Operation("/items/:id", fiber.MethodGet).Responses.Get(code)
+		if response == nil || response.Value.Content.Get(fiber.MIMEApplicationJSON) == nil {
+			t.Errorf("response %d of /items/:id is not documented", code)
+		}
+	}
+}